@@ -6,12 +6,16 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
 	"syscall"
 	"time"
 
 	"github.com/Sea-Chels/go-practice-1/internal/auth"
 	"github.com/Sea-Chels/go-practice-1/internal/database"
 	"github.com/Sea-Chels/go-practice-1/internal/handlers"
+	"github.com/Sea-Chels/go-practice-1/internal/mailer"
+	"github.com/Sea-Chels/go-practice-1/internal/ratelimit"
+	"github.com/Sea-Chels/go-practice-1/internal/students"
 	"github.com/gorilla/mux"
 	"github.com/joho/godotenv"
 )
@@ -28,6 +32,16 @@ func main() {
 	}
 	defer database.CloseDB()
 
+	// Load JWT signing keys
+	if err := auth.InitKeys(); err != nil {
+		log.Fatalf("Failed to load JWT keys: %v", err)
+	}
+
+	// Initialize mail delivery
+	if err := mailer.Init(); err != nil {
+		log.Fatalf("Failed to initialize mailer: %v", err)
+	}
+
 	// Run migrations (unless skipped)
 	if os.Getenv("SKIP_MIGRATIONS") != "true" {
 		if err := database.RunMigrations("migrations"); err != nil {
@@ -42,6 +56,11 @@ func main() {
 		log.Println("Skipping migrations and seeding (SKIP_MIGRATIONS=true)")
 	}
 
+	// Permanently remove students soft-deleted longer than the retention period
+	if days, err := strconv.Atoi(os.Getenv("STUDENT_PURGE_AFTER_DAYS")); err == nil && days > 0 {
+		go purgeDeletedStudents(time.Duration(days) * 24 * time.Hour)
+	}
+
 	// Setup routes
 	router := mux.NewRouter()
 
@@ -50,15 +69,165 @@ func main() {
 	router.Use(loggingMiddleware)
 	router.Use(recoveryMiddleware)
 
+	// Rate limiting: public auth endpoints are limited per client IP,
+	// authenticated endpoints per client IP before authentication, so bad
+	// tokens and API keys are throttled too, and per user or API key after.
+	limiterStore, err := ratelimit.NewStoreFromEnv()
+	if err != nil {
+		log.Fatalf("Failed to initialize rate limiter: %v", err)
+	}
+	if pgStore, ok := limiterStore.(*ratelimit.PostgresStore); ok {
+		go cleanupRateLimitBuckets(pgStore)
+	}
+	authPolicy := ratelimit.ParsePolicy("auth", "RATE_LIMIT_AUTH", ratelimit.Policy{Name: "auth", Limit: 10, Period: time.Minute})
+	apiPolicy := ratelimit.ParsePolicy("api", "RATE_LIMIT_API", ratelimit.Policy{Name: "api", Limit: 300, Period: time.Minute})
+	apiIPPolicy := ratelimit.ParsePolicy("api_ip", "RATE_LIMIT_API_IP", ratelimit.Policy{Name: "api_ip", Limit: 1200, Period: time.Minute})
+
+	public := func(h http.HandlerFunc) http.HandlerFunc {
+		return ratelimit.Limit(limiterStore, authPolicy, h)
+	}
+	// authenticated routes act on the caller's own account and need a user token.
+	authenticated := func(h http.HandlerFunc) http.HandlerFunc {
+		return ratelimit.Limit(limiterStore, apiIPPolicy, auth.JWTMiddleware(ratelimit.Limit(limiterStore, apiPolicy, h)))
+	}
+	// protected routes accept user tokens or API keys holding the permission.
+	protected := func(permission auth.Permission, h http.HandlerFunc) http.HandlerFunc {
+		return ratelimit.Limit(limiterStore, apiIPPolicy, auth.Authenticate(ratelimit.Limit(limiterStore, apiPolicy, auth.RequirePermission(permission, h))))
+	}
+
 	// Public routes
 	router.HandleFunc("/health", handlers.HealthHandler).Methods("GET", "OPTIONS")
-	router.HandleFunc("/auth/login", handlers.LoginHandler).Methods("POST", "OPTIONS")
+	router.HandleFunc("/.well-known/jwks.json", handlers.JWKSHandler).Methods("GET", "OPTIONS")
+	router.HandleFunc("/auth/login", public(handlers.LoginHandler)).Methods("POST", "OPTIONS")
+	router.HandleFunc("/auth/refresh", public(handlers.RefreshHandler)).Methods("POST", "OPTIONS")
+	router.HandleFunc("/auth/logout", public(handlers.LogoutHandler)).Methods("POST", "OPTIONS")
+	router.HandleFunc("/auth/accept-invite", public(handlers.AcceptInvitationHandler)).Methods("POST", "OPTIONS")
+	router.HandleFunc("/auth/mfa/verify", public(handlers.VerifyMFAHandler)).Methods("POST", "OPTIONS")
+	router.HandleFunc("/auth/forgot-password", public(handlers.ForgotPasswordHandler)).Methods("POST", "OPTIONS")
+	router.HandleFunc("/auth/reset-password", public(handlers.ResetPasswordHandler)).Methods("POST", "OPTIONS")
 
 	// Protected routes
-	router.HandleFunc("/students", auth.JWTMiddleware(handlers.GetStudentsHandler)).Methods("GET", "OPTIONS")
-	router.HandleFunc("/students", auth.JWTMiddleware(handlers.CreateStudentHandler)).Methods("POST", "OPTIONS")
-	router.HandleFunc("/students", auth.JWTMiddleware(handlers.UpdateStudentHandler)).Methods("PUT", "OPTIONS")
-	router.HandleFunc("/students/{id}", auth.JWTMiddleware(handlers.DeleteStudentHandler)).Methods("DELETE", "OPTIONS")
+	router.HandleFunc("/students", protected(auth.PermStudentsRead, handlers.GetStudentsHandler)).Methods("GET", "OPTIONS")
+	router.HandleFunc("/students/batch", protected(auth.PermStudentsWrite, handlers.BatchStudentsHandler)).Methods("POST", "OPTIONS")
+	router.HandleFunc("/students/export", protected(auth.PermStudentsRead, handlers.ExportStudentsHandler)).Methods("GET", "OPTIONS")
+	router.HandleFunc("/students/import", protected(auth.PermStudentsWrite, handlers.ImportStudentsHandler)).Methods("POST", "OPTIONS")
+	router.HandleFunc("/students/search", protected(auth.PermStudentsRead, handlers.SearchStudentsHandler)).Methods("GET", "OPTIONS")
+	router.HandleFunc("/students", protected(auth.PermStudentsWrite, handlers.CreateStudentHandler)).Methods("POST", "OPTIONS")
+	router.HandleFunc("/students", protected(auth.PermStudentsWrite, handlers.UpdateStudentHandler)).Methods("PUT", "OPTIONS")
+	router.HandleFunc("/students/{id}", protected(auth.PermStudentsRead, handlers.GetStudentHandler)).Methods("GET", "OPTIONS")
+	router.HandleFunc("/students/{id}", protected(auth.PermStudentsWrite, handlers.ReplaceStudentHandler)).Methods("PUT", "OPTIONS")
+	router.HandleFunc("/students/{id}", protected(auth.PermStudentsWrite, handlers.PatchStudentHandler)).Methods("PATCH", "OPTIONS")
+	router.HandleFunc("/students/{id}", protected(auth.PermStudentsDelete, handlers.DeleteStudentHandler)).Methods("DELETE", "OPTIONS")
+	router.HandleFunc("/students/{id}/restore", protected(auth.PermStudentsDelete, handlers.RestoreStudentHandler)).Methods("POST", "OPTIONS")
+	router.HandleFunc("/students/{id}/purge", protected(auth.PermStudentsPurge, handlers.PurgeStudentHandler)).Methods("DELETE", "OPTIONS")
+
+	router.HandleFunc("/students/{id}/guardians", protected(auth.PermStudentsRead, handlers.GetStudentGuardiansHandler)).Methods("GET", "OPTIONS")
+	router.HandleFunc("/students/{id}/guardians/{guardian_id}", protected(auth.PermStudentsWrite, handlers.LinkGuardianHandler)).Methods("PUT", "OPTIONS")
+	router.HandleFunc("/students/{id}/guardians/{guardian_id}", protected(auth.PermStudentsWrite, handlers.UnlinkGuardianHandler)).Methods("DELETE", "OPTIONS")
+
+	router.HandleFunc("/students/{id}/schedule", protected(auth.PermCoursesRead, handlers.GetStudentScheduleHandler)).Methods("GET", "OPTIONS")
+	router.HandleFunc("/students/{id}/attendance/summary", protected(auth.PermAttendanceRead, handlers.GetStudentAttendanceSummaryHandler)).Methods("GET", "OPTIONS")
+	router.HandleFunc("/students/{id}/grades", protected(auth.PermGradesRead, handlers.GetStudentGradesHandler)).Methods("GET", "OPTIONS")
+
+	router.HandleFunc("/guardians", protected(auth.PermStudentsRead, handlers.GetGuardiansHandler)).Methods("GET", "OPTIONS")
+	router.HandleFunc("/guardians", protected(auth.PermStudentsWrite, handlers.CreateGuardianHandler)).Methods("POST", "OPTIONS")
+	router.HandleFunc("/guardians/{id}", protected(auth.PermStudentsRead, handlers.GetGuardianHandler)).Methods("GET", "OPTIONS")
+	router.HandleFunc("/guardians/{id}", protected(auth.PermStudentsWrite, handlers.UpdateGuardianHandler)).Methods("PUT", "OPTIONS")
+	router.HandleFunc("/guardians/{id}", protected(auth.PermStudentsDelete, handlers.DeleteGuardianHandler)).Methods("DELETE", "OPTIONS")
+	router.HandleFunc("/guardians/{id}/students", protected(auth.PermStudentsRead, handlers.GetGuardianStudentsHandler)).Methods("GET", "OPTIONS")
+
+	router.HandleFunc("/promotions", protected(auth.PermStudentsPromote, handlers.GetPromotionRunsHandler)).Methods("GET", "OPTIONS")
+	router.HandleFunc("/promotions", protected(auth.PermStudentsPromote, handlers.ExecutePromotionHandler)).Methods("POST", "OPTIONS")
+	router.HandleFunc("/promotions/preview", protected(auth.PermStudentsPromote, handlers.PreviewPromotionHandler)).Methods("POST", "OPTIONS")
+	router.HandleFunc("/promotions/{id}/revert", protected(auth.PermStudentsPromote, handlers.RevertPromotionHandler)).Methods("POST", "OPTIONS")
+
+	router.HandleFunc("/teachers", protected(auth.PermTeachersRead, handlers.GetTeachersHandler)).Methods("GET", "OPTIONS")
+	router.HandleFunc("/teachers", protected(auth.PermTeachersWrite, handlers.CreateTeacherHandler)).Methods("POST", "OPTIONS")
+	router.HandleFunc("/teachers/{id}", protected(auth.PermTeachersRead, handlers.GetTeacherHandler)).Methods("GET", "OPTIONS")
+	router.HandleFunc("/teachers/{id}", protected(auth.PermTeachersWrite, handlers.UpdateTeacherHandler)).Methods("PUT", "OPTIONS")
+	router.HandleFunc("/teachers/{id}", protected(auth.PermTeachersDelete, handlers.DeleteTeacherHandler)).Methods("DELETE", "OPTIONS")
+	router.HandleFunc("/teachers/{id}/restore", protected(auth.PermTeachersDelete, handlers.RestoreTeacherHandler)).Methods("POST", "OPTIONS")
+
+	router.HandleFunc("/courses", protected(auth.PermCoursesRead, handlers.GetCoursesHandler)).Methods("GET", "OPTIONS")
+	router.HandleFunc("/courses", protected(auth.PermCoursesWrite, handlers.CreateCourseHandler)).Methods("POST", "OPTIONS")
+	router.HandleFunc("/courses/{id}", protected(auth.PermCoursesRead, handlers.GetCourseHandler)).Methods("GET", "OPTIONS")
+	router.HandleFunc("/courses/{id}", protected(auth.PermCoursesWrite, handlers.UpdateCourseHandler)).Methods("PUT", "OPTIONS")
+	router.HandleFunc("/courses/{id}", protected(auth.PermCoursesDelete, handlers.DeleteCourseHandler)).Methods("DELETE", "OPTIONS")
+
+	router.HandleFunc("/sections", protected(auth.PermCoursesRead, handlers.GetSectionsHandler)).Methods("GET", "OPTIONS")
+	router.HandleFunc("/sections", protected(auth.PermCoursesWrite, handlers.CreateSectionHandler)).Methods("POST", "OPTIONS")
+	router.HandleFunc("/sections/{id}", protected(auth.PermCoursesRead, handlers.GetSectionHandler)).Methods("GET", "OPTIONS")
+	router.HandleFunc("/sections/{id}", protected(auth.PermCoursesWrite, handlers.UpdateSectionHandler)).Methods("PUT", "OPTIONS")
+	router.HandleFunc("/sections/{id}", protected(auth.PermCoursesDelete, handlers.DeleteSectionHandler)).Methods("DELETE", "OPTIONS")
+	router.HandleFunc("/sections/{id}/roster", protected(auth.PermCoursesRead, handlers.GetSectionRosterHandler)).Methods("GET", "OPTIONS")
+	router.HandleFunc("/sections/{id}/enrollments", protected(auth.PermCoursesWrite, handlers.EnrollStudentHandler)).Methods("POST", "OPTIONS")
+	router.HandleFunc("/sections/{id}/enrollments/{student_id}", protected(auth.PermCoursesWrite, handlers.DropEnrollmentHandler)).Methods("DELETE", "OPTIONS")
+
+	router.HandleFunc("/academic-years", protected(auth.PermCalendarRead, handlers.GetAcademicYearsHandler)).Methods("GET", "OPTIONS")
+	router.HandleFunc("/academic-years", protected(auth.PermCalendarWrite, handlers.CreateAcademicYearHandler)).Methods("POST", "OPTIONS")
+	router.HandleFunc("/academic-years/{id}", protected(auth.PermCalendarRead, handlers.GetAcademicYearHandler)).Methods("GET", "OPTIONS")
+	router.HandleFunc("/academic-years/{id}", protected(auth.PermCalendarWrite, handlers.UpdateAcademicYearHandler)).Methods("PUT", "OPTIONS")
+	router.HandleFunc("/academic-years/{id}", protected(auth.PermCalendarWrite, handlers.DeleteAcademicYearHandler)).Methods("DELETE", "OPTIONS")
+
+	router.HandleFunc("/terms", protected(auth.PermCalendarRead, handlers.GetTermsHandler)).Methods("GET", "OPTIONS")
+	router.HandleFunc("/terms", protected(auth.PermCalendarWrite, handlers.CreateTermHandler)).Methods("POST", "OPTIONS")
+	router.HandleFunc("/terms/current", protected(auth.PermCalendarRead, handlers.GetCurrentTermHandler)).Methods("GET", "OPTIONS")
+	router.HandleFunc("/terms/{id}", protected(auth.PermCalendarRead, handlers.GetTermHandler)).Methods("GET", "OPTIONS")
+	router.HandleFunc("/terms/{id}", protected(auth.PermCalendarWrite, handlers.UpdateTermHandler)).Methods("PUT", "OPTIONS")
+	router.HandleFunc("/terms/{id}", protected(auth.PermCalendarWrite, handlers.DeleteTermHandler)).Methods("DELETE", "OPTIONS")
+
+	router.HandleFunc("/calendar-events", protected(auth.PermCalendarRead, handlers.GetCalendarEventsHandler)).Methods("GET", "OPTIONS")
+	router.HandleFunc("/calendar-events", protected(auth.PermCalendarWrite, handlers.CreateCalendarEventHandler)).Methods("POST", "OPTIONS")
+	router.HandleFunc("/calendar-events/{id}", protected(auth.PermCalendarWrite, handlers.UpdateCalendarEventHandler)).Methods("PUT", "OPTIONS")
+	router.HandleFunc("/calendar-events/{id}", protected(auth.PermCalendarWrite, handlers.DeleteCalendarEventHandler)).Methods("DELETE", "OPTIONS")
+
+	router.HandleFunc("/attendance", protected(auth.PermAttendanceRead, handlers.GetAttendanceHandler)).Methods("GET", "OPTIONS")
+	router.HandleFunc("/attendance/roster", protected(auth.PermAttendanceWrite, handlers.RecordRosterAttendanceHandler)).Methods("POST", "OPTIONS")
+	router.HandleFunc("/attendance/{id}", protected(auth.PermAttendanceRead, handlers.GetAttendanceRecordHandler)).Methods("GET", "OPTIONS")
+	router.HandleFunc("/attendance/{id}/corrections", protected(auth.PermAttendanceWrite, handlers.CorrectAttendanceHandler)).Methods("POST", "OPTIONS")
+
+	router.HandleFunc("/grading-scales", protected(auth.PermGradesRead, handlers.GetGradingScalesHandler)).Methods("GET", "OPTIONS")
+	router.HandleFunc("/grading-scales", protected(auth.PermCoursesWrite, handlers.CreateGradingScaleHandler)).Methods("POST", "OPTIONS")
+	router.HandleFunc("/grading-scales/{id}", protected(auth.PermGradesRead, handlers.GetGradingScaleHandler)).Methods("GET", "OPTIONS")
+	router.HandleFunc("/grading-scales/{id}", protected(auth.PermCoursesWrite, handlers.UpdateGradingScaleHandler)).Methods("PUT", "OPTIONS")
+	router.HandleFunc("/grading-scales/{id}", protected(auth.PermCoursesWrite, handlers.DeleteGradingScaleHandler)).Methods("DELETE", "OPTIONS")
+
+	router.HandleFunc("/sections/{id}/grade-categories", protected(auth.PermGradesRead, handlers.GetGradeCategoriesHandler)).Methods("GET", "OPTIONS")
+	router.HandleFunc("/sections/{id}/grade-categories", protected(auth.PermGradesWrite, handlers.CreateGradeCategoryHandler)).Methods("POST", "OPTIONS")
+	router.HandleFunc("/grade-categories/{id}", protected(auth.PermGradesWrite, handlers.UpdateGradeCategoryHandler)).Methods("PUT", "OPTIONS")
+	router.HandleFunc("/grade-categories/{id}", protected(auth.PermGradesWrite, handlers.DeleteGradeCategoryHandler)).Methods("DELETE", "OPTIONS")
+
+	router.HandleFunc("/sections/{id}/assignments", protected(auth.PermGradesRead, handlers.GetSectionAssignmentsHandler)).Methods("GET", "OPTIONS")
+	router.HandleFunc("/sections/{id}/assignments", protected(auth.PermGradesWrite, handlers.CreateAssignmentHandler)).Methods("POST", "OPTIONS")
+	router.HandleFunc("/assignments/{id}", protected(auth.PermGradesRead, handlers.GetAssignmentHandler)).Methods("GET", "OPTIONS")
+	router.HandleFunc("/assignments/{id}", protected(auth.PermGradesWrite, handlers.UpdateAssignmentHandler)).Methods("PUT", "OPTIONS")
+	router.HandleFunc("/assignments/{id}", protected(auth.PermGradesWrite, handlers.DeleteAssignmentHandler)).Methods("DELETE", "OPTIONS")
+	router.HandleFunc("/assignments/{id}/scores", protected(auth.PermGradesRead, handlers.GetAssignmentScoresHandler)).Methods("GET", "OPTIONS")
+	router.HandleFunc("/assignments/{id}/scores", protected(auth.PermGradesWrite, handlers.RecordScoresHandler)).Methods("PUT", "OPTIONS")
+	router.HandleFunc("/sections/{id}/grades", protected(auth.PermGradesRead, handlers.GetSectionGradesHandler)).Methods("GET", "OPTIONS")
+
+	router.HandleFunc("/me", authenticated(handlers.GetMeHandler)).Methods("GET", "OPTIONS")
+	router.HandleFunc("/me", authenticated(handlers.UpdateMeHandler)).Methods("PATCH", "OPTIONS")
+	router.HandleFunc("/me/password", authenticated(handlers.ChangePasswordHandler)).Methods("POST", "OPTIONS")
+	router.HandleFunc("/me/mfa/enroll", authenticated(handlers.EnrollMFAHandler)).Methods("POST", "OPTIONS")
+	router.HandleFunc("/me/mfa/confirm", authenticated(handlers.ConfirmMFAHandler)).Methods("POST", "OPTIONS")
+	router.HandleFunc("/me/mfa/disable", authenticated(handlers.DisableMFAHandler)).Methods("POST", "OPTIONS")
+
+	router.HandleFunc("/users", protected(auth.PermUsersManage, handlers.GetUsersHandler)).Methods("GET", "OPTIONS")
+	router.HandleFunc("/users/invitations", protected(auth.PermUsersManage, handlers.GetInvitationsHandler)).Methods("GET", "OPTIONS")
+	router.HandleFunc("/users/invitations", protected(auth.PermUsersManage, handlers.CreateInvitationHandler)).Methods("POST", "OPTIONS")
+	router.HandleFunc("/users/invitations/{id}", protected(auth.PermUsersManage, handlers.RevokeInvitationHandler)).Methods("DELETE", "OPTIONS")
+	router.HandleFunc("/users/{id}/disable", protected(auth.PermUsersManage, handlers.DisableUserHandler)).Methods("POST", "OPTIONS")
+	router.HandleFunc("/users/{id}/enable", protected(auth.PermUsersManage, handlers.EnableUserHandler)).Methods("POST", "OPTIONS")
+	router.HandleFunc("/users/{id}/unlock", protected(auth.PermUsersManage, handlers.UnlockUserHandler)).Methods("POST", "OPTIONS")
+	router.HandleFunc("/users/{id}/mfa/reset", protected(auth.PermUsersManage, handlers.ResetUserMFAHandler)).Methods("POST", "OPTIONS")
+	router.HandleFunc("/users/{id}", protected(auth.PermUsersManage, handlers.DeleteUserHandler)).Methods("DELETE", "OPTIONS")
+
+	router.HandleFunc("/api-keys", protected(auth.PermAPIKeysManage, handlers.GetAPIKeysHandler)).Methods("GET", "OPTIONS")
+	router.HandleFunc("/api-keys", protected(auth.PermAPIKeysManage, handlers.CreateAPIKeyHandler)).Methods("POST", "OPTIONS")
+	router.HandleFunc("/api-keys/{id}", protected(auth.PermAPIKeysManage, handlers.RevokeAPIKeyHandler)).Methods("DELETE", "OPTIONS")
+
+	router.HandleFunc("/audit-logs", protected(auth.PermAuditRead, handlers.GetAuditLogsHandler)).Methods("GET", "OPTIONS")
 
 	// Server configuration
 	port := os.Getenv("PORT")
@@ -103,8 +272,9 @@ func corsMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// For development, allow all origins
 		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-API-Key, If-Match, If-None-Match")
+		w.Header().Set("Access-Control-Expose-Headers", "ETag, RateLimit-Limit, RateLimit-Remaining, RateLimit-Reset, Retry-After")
 		w.Header().Set("Access-Control-Max-Age", "86400")
 
 		if r.Method == "OPTIONS" {
@@ -116,6 +286,41 @@ func corsMiddleware(next http.Handler) http.Handler {
 	})
 }
 
+// cleanupRateLimitBuckets periodically removes idle buckets from Postgres.
+func cleanupRateLimitBuckets(store *ratelimit.PostgresStore) {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		deleted, err := store.Cleanup(24 * time.Hour)
+		if err != nil {
+			log.Printf("Failed to clean up rate limit buckets: %v", err)
+			continue
+		}
+		if deleted > 0 {
+			log.Printf("Removed %d idle rate limit buckets", deleted)
+		}
+	}
+}
+
+// purgeDeletedStudents periodically removes students whose soft delete is
+// older than retention.
+func purgeDeletedStudents(retention time.Duration) {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+
+	for ; ; <-ticker.C {
+		purged, err := students.PurgeDeleted(database.DB, time.Now().Add(-retention))
+		if err != nil {
+			log.Printf("Failed to purge deleted students: %v", err)
+			continue
+		}
+		if purged > 0 {
+			log.Printf("Purged %d students deleted more than %s ago", purged, retention)
+		}
+	}
+}
+
 func loggingMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		start := time.Now()
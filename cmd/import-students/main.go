@@ -0,0 +1,60 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+
+	"github.com/Sea-Chels/go-practice-1/internal/database"
+	"github.com/Sea-Chels/go-practice-1/internal/students"
+	"github.com/joho/godotenv"
+)
+
+// import-students loads a CSV roster the same way as POST /students/import
+// and prints the report as JSON. It exits with status 1 if any row failed.
+func main() {
+	dryRun := flag.Bool("dry-run", false, "validate the file without saving anything")
+	flag.Usage = func() {
+		log.Printf("Usage: %s [-dry-run] <file.csv>", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	// Load environment variables
+	if err := godotenv.Load(); err != nil {
+		log.Println("No .env file found, using environment variables")
+	}
+
+	file, err := os.Open(flag.Arg(0))
+	if err != nil {
+		log.Fatalf("Failed to open %s: %v", flag.Arg(0), err)
+	}
+	defer file.Close()
+
+	// Initialize database connection
+	if err := database.InitDB(); err != nil {
+		log.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer database.CloseDB()
+
+	report, err := students.Import(database.DB, file, students.ImportOptions{DryRun: *dryRun})
+	if err != nil {
+		log.Fatalf("Import failed: %v", err)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		log.Fatalf("Failed to write report: %v", err)
+	}
+
+	if report.Failed > 0 {
+		database.CloseDB()
+		os.Exit(1)
+	}
+}
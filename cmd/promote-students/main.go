@@ -0,0 +1,87 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/Sea-Chels/go-practice-1/internal/database"
+	"github.com/Sea-Chels/go-practice-1/internal/models"
+	"github.com/Sea-Chels/go-practice-1/internal/students"
+	"github.com/joho/godotenv"
+)
+
+// promote-students runs the end-of-year promotion the same way as the
+// /promotions endpoints and prints the result as JSON.
+//
+//	promote-students [-hold-back 12,40] [-graduation-date 2025-06-20] [-force] preview|execute
+//	promote-students revert <run-id>
+func main() {
+	holdBack := flag.String("hold-back", "", "comma-separated IDs of students who stay in their grade")
+	graduationDate := flag.String("graduation-date", "", "graduation date (YYYY-MM-DD), defaults to today")
+	force := flag.Bool("force", false, "run even if a promotion ran in the last 30 days")
+	flag.Usage = func() {
+		log.Printf("Usage: %s [flags] preview|execute\n       %s revert <run-id>", os.Args[0], os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	command := flag.Arg(0)
+	if (command != "preview" && command != "execute" || flag.NArg() != 1) && (command != "revert" || flag.NArg() != 2) {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	req := models.PromotionRequest{GraduationDate: *graduationDate, Force: *force}
+	for _, value := range strings.Split(*holdBack, ",") {
+		if value = strings.TrimSpace(value); value == "" {
+			continue
+		}
+		id, err := strconv.Atoi(value)
+		if err != nil {
+			log.Fatalf("Invalid student ID in -hold-back: %q", value)
+		}
+		req.HoldBack = append(req.HoldBack, id)
+	}
+	opts, err := students.ParsePromotionRequest(req)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Load environment variables
+	if err := godotenv.Load(); err != nil {
+		log.Println("No .env file found, using environment variables")
+	}
+
+	// Initialize database connection
+	if err := database.InitDB(); err != nil {
+		log.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer database.CloseDB()
+
+	var result interface{}
+	switch command {
+	case "preview":
+		result, err = students.PreviewPromotion(database.DB, opts)
+	case "execute":
+		result, err = students.ExecutePromotion(database.DB, opts)
+	case "revert":
+		runID, convErr := strconv.Atoi(flag.Arg(1))
+		if convErr != nil {
+			log.Fatalf("Invalid run ID: %q", flag.Arg(1))
+		}
+		result, err = students.RevertPromotion(database.DB, runID, nil, "")
+	}
+	if err != nil {
+		log.Fatalf("Promotion %s failed: %v", command, err)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(result); err != nil {
+		log.Fatalf("Failed to write result: %v", err)
+	}
+}
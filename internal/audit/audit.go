@@ -0,0 +1,62 @@
+package audit
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+)
+
+// Common actions recorded in audit_logs.
+const (
+	ActionAccountLocked       = "auth.account_locked"
+	ActionAccountUnlocked     = "auth.account_unlocked"
+	ActionStudentPurged       = "student.purged"
+	ActionStudentsImported    = "student.imported"
+	ActionStudentsBatch       = "student.batch"
+	ActionPromotionExecuted   = "student.promotion_executed"
+	ActionPromotionReverted   = "student.promotion_reverted"
+	ActionAttendanceCorrected = "attendance.corrected"
+)
+
+type Entry struct {
+	// ActorID is the user who performed the action, nil for system actions.
+	ActorID    *int
+	Action     string
+	EntityType string
+	EntityID   string
+	Details    map[string]interface{}
+	IPAddress  string
+}
+
+// Execer is satisfied by both *sql.DB and *sql.Tx so entries can be written
+// inside the transaction of the change they describe.
+type Execer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+func Record(db Execer, entry Entry) error {
+	var details interface{}
+	if entry.Details != nil {
+		encoded, err := json.Marshal(entry.Details)
+		if err != nil {
+			return fmt.Errorf("failed to encode audit details: %w", err)
+		}
+		details = string(encoded)
+	}
+
+	_, err := db.Exec(`
+		INSERT INTO audit_logs (actor_user_id, action, entity_type, entity_id, details, ip_address)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`, entry.ActorID, entry.Action, entry.EntityType, nullIfEmpty(entry.EntityID), details, nullIfEmpty(entry.IPAddress))
+	if err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	return nil
+}
+
+func nullIfEmpty(value string) interface{} {
+	if value == "" {
+		return nil
+	}
+	return value
+}
@@ -0,0 +1,97 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Sea-Chels/go-practice-1/internal/database"
+	"github.com/lib/pq"
+)
+
+const (
+	apiKeyPrefix = "sk_"
+	// apiKeyTouchInterval limits how often last_used_at is written for a busy key.
+	apiKeyTouchInterval = time.Minute
+)
+
+var errInvalidAPIKey = fmt.Errorf("invalid API key")
+
+// GenerateAPIKey returns a new key of the form sk_<prefix>_<secret>, its
+// lookup prefix and the hash to persist.
+func GenerateAPIKey() (key string, prefix string, hash string, err error) {
+	idBytes := make([]byte, 4)
+	secretBytes := make([]byte, 32)
+	if _, err := rand.Read(idBytes); err != nil {
+		return "", "", "", fmt.Errorf("failed to generate API key: %w", err)
+	}
+	if _, err := rand.Read(secretBytes); err != nil {
+		return "", "", "", fmt.Errorf("failed to generate API key: %w", err)
+	}
+
+	prefix = hex.EncodeToString(idBytes)
+	key = apiKeyPrefix + prefix + "_" + base64.RawURLEncoding.EncodeToString(secretBytes)
+	return key, prefix, HashToken(key), nil
+}
+
+// ValidateAPIKey looks up an API key and returns claims carrying its scopes.
+// Requests made with the key act on behalf of the user who created it, so
+// the key stops working once that user is disabled or deleted.
+func ValidateAPIKey(key string) (*Claims, error) {
+	parts := strings.SplitN(strings.TrimPrefix(key, apiKeyPrefix), "_", 2)
+	if !strings.HasPrefix(key, apiKeyPrefix) || len(parts) != 2 {
+		return nil, errInvalidAPIKey
+	}
+
+	var (
+		id         int
+		keyHash    string
+		scopes     []string
+		createdBy  int
+		email      string
+		role       string
+		expiresAt  sql.NullTime
+		lastUsedAt sql.NullTime
+	)
+	err := database.DB.QueryRow(`
+		SELECT k.id, k.key_hash, k.scopes, u.id, u.email, u.role, k.expires_at, k.last_used_at
+		FROM api_keys k
+		JOIN users u ON u.id = k.created_by
+		WHERE k.prefix = $1 AND k.revoked_at IS NULL
+		  AND u.deleted_at IS NULL AND u.disabled_at IS NULL
+	`, parts[0]).Scan(&id, &keyHash, pq.Array(&scopes), &createdBy, &email, &role, &expiresAt, &lastUsedAt)
+
+	if err == sql.ErrNoRows {
+		return nil, errInvalidAPIKey
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to look up API key: %w", err)
+	}
+
+	if subtle.ConstantTimeCompare([]byte(keyHash), []byte(HashToken(key))) != 1 {
+		return nil, errInvalidAPIKey
+	}
+
+	now := time.Now()
+	if expiresAt.Valid && now.After(expiresAt.Time) {
+		return nil, errInvalidAPIKey
+	}
+
+	if !lastUsedAt.Valid || now.Sub(lastUsedAt.Time) > apiKeyTouchInterval {
+		if _, err := database.DB.Exec(`UPDATE api_keys SET last_used_at = $2 WHERE id = $1`, id, now); err != nil {
+			return nil, fmt.Errorf("failed to update API key: %w", err)
+		}
+	}
+
+	return &Claims{
+		UserID:   createdBy,
+		Email:    email,
+		Role:     role,
+		APIKeyID: id,
+		Scopes:   scopes,
+	}, nil
+}
@@ -2,38 +2,93 @@ package auth
 
 import (
 	"fmt"
-	"os"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 )
 
+// PurposeMFA marks a short-lived token that only proves the password step of
+// a two-step login. Access tokens have no purpose.
+const PurposeMFA = "mfa"
+
+const mfaTokenTTL = 5 * time.Minute
+
 type Claims struct {
-	UserID int    `json:"user_id"`
-	Email  string `json:"email"`
+	UserID  int    `json:"user_id"`
+	Email   string `json:"email"`
+	Role    string `json:"role"`
+	Purpose string `json:"purpose,omitempty"`
+	// APIKeyID and Scopes are set when the request was authenticated with an
+	// API key, alongside the creator's current Role; they never appear in a
+	// JWT.
+	APIKeyID int      `json:"-"`
+	Scopes   []string `json:"-"`
 	jwt.RegisteredClaims
 }
 
-func GenerateToken(userID int, email string) (string, time.Time, error) {
-	secret := os.Getenv("JWT_SECRET")
-	if secret == "" {
-		return "", time.Time{}, fmt.Errorf("JWT_SECRET not configured")
+func GenerateToken(userID int, email string, role string) (string, time.Time, error) {
+	return signClaims(&Claims{
+		UserID: userID,
+		Email:  email,
+		Role:   role,
+	}, AccessTokenTTL())
+}
+
+// GenerateMFAToken issues the challenge token returned by the login endpoint
+// when the user has two-factor authentication enabled. It is signed with a
+// separate, unpublished key, so it never validates as an access token.
+func GenerateMFAToken(userID int, email string) (string, time.Time, error) {
+	return signClaims(&Claims{
+		UserID:  userID,
+		Email:   email,
+		Purpose: PurposeMFA,
+	}, mfaTokenTTL)
+}
+
+func ValidateToken(tokenString string) (*Claims, error) {
+	claims, err := parseClaims(tokenString, "")
+	if err != nil {
+		return nil, err
 	}
 
-	expirationHours := 24
-	expirationTime := time.Now().Add(time.Duration(expirationHours) * time.Hour)
+	if claims.Purpose != "" {
+		return nil, fmt.Errorf("token is not an access token")
+	}
 
-	claims := &Claims{
-		UserID: userID,
-		Email:  email,
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(expirationTime),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-		},
+	return claims, nil
+}
+
+func ValidateMFAToken(tokenString string) (*Claims, error) {
+	claims, err := parseClaims(tokenString, PurposeMFA)
+	if err != nil {
+		return nil, err
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString([]byte(secret))
+	if claims.Purpose != PurposeMFA {
+		return nil, fmt.Errorf("token is not an MFA challenge token")
+	}
+
+	return claims, nil
+}
+
+func signClaims(claims *Claims, ttl time.Duration) (string, time.Time, error) {
+	set, err := currentKeys()
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	now := time.Now()
+	expirationTime := now.Add(ttl)
+	claims.RegisteredClaims = jwt.RegisteredClaims{
+		ExpiresAt: jwt.NewNumericDate(expirationTime),
+		IssuedAt:  jwt.NewNumericDate(now),
+	}
+
+	sign := set.sign
+	if claims.Purpose == PurposeMFA {
+		sign = set.signMFA
+	}
+	tokenString, err := sign(claims)
 	if err != nil {
 		return "", time.Time{}, fmt.Errorf("failed to sign token: %w", err)
 	}
@@ -41,19 +96,21 @@ func GenerateToken(userID int, email string) (string, time.Time, error) {
 	return tokenString, expirationTime, nil
 }
 
-func ValidateToken(tokenString string) (*Claims, error) {
-	secret := os.Getenv("JWT_SECRET")
-	if secret == "" {
-		return nil, fmt.Errorf("JWT_SECRET not configured")
+// parseClaims verifies a token with the keys for purpose: the MFA secret for
+// challenge tokens and the published keys otherwise.
+func parseClaims(tokenString string, purpose string) (*Claims, error) {
+	set, err := currentKeys()
+	if err != nil {
+		return nil, err
+	}
+
+	keyFunc := set.keyFunc
+	if purpose == PurposeMFA {
+		keyFunc = set.mfaKeyFunc
 	}
 
 	claims := &Claims{}
-	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return []byte(secret), nil
-	})
+	token, err := jwt.ParseWithClaims(tokenString, claims, keyFunc)
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse token: %w", err)
@@ -64,4 +121,4 @@ func ValidateToken(tokenString string) (*Claims, error) {
 	}
 
 	return claims, nil
-}
\ No newline at end of file
+}
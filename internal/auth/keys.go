@@ -0,0 +1,312 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// verificationKey is a public key that tokens may be signed with.
+type verificationKey struct {
+	id     string
+	method jwt.SigningMethod
+	public crypto.PublicKey
+}
+
+// keySet holds the key used to sign new tokens and every key accepted when
+// validating them. In legacy mode only hmacSecret is set. MFA challenge
+// tokens are signed with mfaSecret instead, which is never published, so
+// services validating against the JWKS cannot mistake them for access
+// tokens.
+type keySet struct {
+	signingID     string
+	signingMethod jwt.SigningMethod
+	signingKey    crypto.PrivateKey
+	verification  map[string]*verificationKey
+	hmacSecret    []byte
+	mfaSecret     []byte
+}
+
+var (
+	keysMu   sync.RWMutex
+	keys     *keySet
+	keysOnce sync.Once
+	keysErr  error
+)
+
+// InitKeys loads the signing configuration from the environment.
+//
+// When JWT_KEYS_DIR is set every *.pem file in it is loaded, using the file
+// name without extension as the key ID. Private keys (RSA or Ed25519) can sign
+// and verify; public keys only verify, which lets a retired key keep
+// validating tokens until they expire. JWT_SIGNING_KID selects the private
+// key used to sign new tokens.
+//
+// Without JWT_KEYS_DIR tokens are signed with HS256 using JWT_SECRET.
+func InitKeys() error {
+	loaded, err := loadKeySet()
+	if err != nil {
+		return err
+	}
+
+	keysMu.Lock()
+	keys = loaded
+	keysMu.Unlock()
+	return nil
+}
+
+// currentKeys returns the loaded key set, loading it on first use if InitKeys
+// was never called.
+func currentKeys() (*keySet, error) {
+	keysMu.RLock()
+	loaded := keys
+	keysMu.RUnlock()
+	if loaded != nil {
+		return loaded, nil
+	}
+
+	keysOnce.Do(func() { keysErr = InitKeys() })
+	if keysErr != nil {
+		return nil, keysErr
+	}
+
+	keysMu.RLock()
+	defer keysMu.RUnlock()
+	return keys, nil
+}
+
+func loadKeySet() (*keySet, error) {
+	dir := os.Getenv("JWT_KEYS_DIR")
+	if dir == "" {
+		secret := os.Getenv("JWT_SECRET")
+		if secret == "" {
+			return nil, fmt.Errorf("JWT_SECRET not configured")
+		}
+		log.Println("JWT signing with HS256 (set JWT_KEYS_DIR to use asymmetric keys)")
+		return &keySet{
+			signingMethod: jwt.SigningMethodHS256,
+			hmacSecret:    []byte(secret),
+			mfaSecret:     deriveMFASecret([]byte(secret)),
+		}, nil
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list JWT keys: %w", err)
+	}
+	sort.Strings(files)
+
+	set := &keySet{verification: map[string]*verificationKey{}}
+	privateKeys := map[string]crypto.PrivateKey{}
+	for _, file := range files {
+		kid := strings.TrimSuffix(filepath.Base(file), ".pem")
+		private, public, err := parseKeyFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load JWT key %s: %w", kid, err)
+		}
+
+		method, err := signingMethodFor(public)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load JWT key %s: %w", kid, err)
+		}
+
+		set.verification[kid] = &verificationKey{id: kid, method: method, public: public}
+		if private != nil {
+			privateKeys[kid] = private
+		}
+	}
+
+	kid := os.Getenv("JWT_SIGNING_KID")
+	if kid == "" {
+		return nil, fmt.Errorf("JWT_SIGNING_KID not configured")
+	}
+	private, ok := privateKeys[kid]
+	if !ok {
+		return nil, fmt.Errorf("no private key found for JWT_SIGNING_KID %q in %s", kid, dir)
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load JWT key %s: %w", kid, err)
+	}
+
+	set.signingID = kid
+	set.signingMethod = set.verification[kid].method
+	set.signingKey = private
+	set.mfaSecret = deriveMFASecret(der)
+
+	log.Printf("JWT signing with %s key %q (%d verification keys)", set.signingMethod.Alg(), kid, len(set.verification))
+	return set, nil
+}
+
+// deriveMFASecret derives the MFA challenge signing secret from the signing
+// key material, so every replica sharing the key agrees on it without more
+// configuration.
+func deriveMFASecret(material []byte) []byte {
+	mac := hmac.New(sha256.New, material)
+	mac.Write([]byte("mfa-challenge-token"))
+	return mac.Sum(nil)
+}
+
+// parseKeyFile reads a PEM encoded private or public key. For private keys
+// the matching public key is returned as well.
+func parseKeyFile(path string) (crypto.PrivateKey, crypto.PublicKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, nil, fmt.Errorf("no PEM data found")
+	}
+
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		key, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, nil, err
+		}
+		return key, &key.PublicKey, nil
+	case "PRIVATE KEY":
+		key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, nil, err
+		}
+		switch k := key.(type) {
+		case *rsa.PrivateKey:
+			return k, &k.PublicKey, nil
+		case ed25519.PrivateKey:
+			return k, k.Public(), nil
+		}
+		return nil, nil, fmt.Errorf("unsupported private key type %T", key)
+	case "RSA PUBLIC KEY":
+		key, err := x509.ParsePKCS1PublicKey(block.Bytes)
+		return nil, key, err
+	case "PUBLIC KEY":
+		key, err := x509.ParsePKIXPublicKey(block.Bytes)
+		return nil, key, err
+	}
+
+	return nil, nil, fmt.Errorf("unsupported PEM block %q", block.Type)
+}
+
+func signingMethodFor(public crypto.PublicKey) (jwt.SigningMethod, error) {
+	switch public.(type) {
+	case *rsa.PublicKey:
+		return jwt.SigningMethodRS256, nil
+	case ed25519.PublicKey:
+		return jwt.SigningMethodEdDSA, nil
+	}
+	return nil, fmt.Errorf("unsupported key type %T", public)
+}
+
+// keyFunc resolves the verification key for a token from its kid header and
+// refuses tokens whose algorithm does not match that key.
+func (s *keySet) keyFunc(token *jwt.Token) (interface{}, error) {
+	if s.hmacSecret != nil {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return s.hmacSecret, nil
+	}
+
+	kid, _ := token.Header["kid"].(string)
+	key, ok := s.verification[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown key ID %q", kid)
+	}
+	if token.Method.Alg() != key.method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+	}
+	return key.public, nil
+}
+
+func (s *keySet) sign(claims *Claims) (string, error) {
+	token := jwt.NewWithClaims(s.signingMethod, claims)
+	if s.hmacSecret != nil {
+		return token.SignedString(s.hmacSecret)
+	}
+
+	token.Header["kid"] = s.signingID
+	return token.SignedString(s.signingKey)
+}
+
+// mfaKeyFunc accepts only HS256 tokens signed with the MFA secret.
+func (s *keySet) mfaKeyFunc(token *jwt.Token) (interface{}, error) {
+	if token.Method != jwt.SigningMethodHS256 {
+		return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+	}
+	return s.mfaSecret, nil
+}
+
+func (s *keySet) signMFA(claims *Claims) (string, error) {
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(s.mfaSecret)
+}
+
+// JWK is a public key in JSON Web Key format (RFC 7517).
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	// RSA
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+	// Ed25519
+	Curve string `json:"crv,omitempty"`
+	X     string `json:"x,omitempty"`
+}
+
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// PublicJWKS returns every verification key so other services can validate
+// tokens. It is empty in HS256 mode, where there is no public key to share.
+func PublicJWKS() (JWKSet, error) {
+	set, err := currentKeys()
+	if err != nil {
+		return JWKSet{}, err
+	}
+
+	jwks := JWKSet{Keys: []JWK{}}
+	ids := make([]string, 0, len(set.verification))
+	for kid := range set.verification {
+		ids = append(ids, kid)
+	}
+	sort.Strings(ids)
+
+	for _, kid := range ids {
+		key := set.verification[kid]
+		jwk := JWK{KeyID: kid, Use: "sig", Algorithm: key.method.Alg()}
+		switch public := key.public.(type) {
+		case *rsa.PublicKey:
+			jwk.KeyType = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(public.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.KeyType = "OKP"
+			jwk.Curve = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(public)
+		}
+		jwks.Keys = append(jwks.Keys, jwk)
+	}
+
+	return jwks, nil
+}
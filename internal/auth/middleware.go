@@ -2,6 +2,7 @@ package auth
 
 import (
 	"context"
+	"log"
 	"net/http"
 	"strings"
 
@@ -41,6 +42,36 @@ func JWTMiddleware(next http.HandlerFunc) http.HandlerFunc {
 	}
 }
 
+// APIKeyHeader carries an API key for service-to-service requests.
+const APIKeyHeader = "X-API-Key"
+
+// Authenticate accepts either a Bearer JWT or an API key in the X-API-Key
+// header. Routes that act on the caller's own account should keep using
+// JWTMiddleware, which only accepts user tokens.
+func Authenticate(next http.HandlerFunc) http.HandlerFunc {
+	jwtNext := JWTMiddleware(next)
+	return func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(APIKeyHeader)
+		if key == "" {
+			jwtNext(w, r)
+			return
+		}
+
+		claims, err := ValidateAPIKey(key)
+		if err == errInvalidAPIKey {
+			utils.ErrorResponse(w, "Invalid or expired API key", http.StatusUnauthorized)
+			return
+		} else if err != nil {
+			log.Printf("Authenticate: %v", err)
+			utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), UserContextKey, claims)
+		next(w, r.WithContext(ctx))
+	}
+}
+
 func GetUserFromContext(ctx context.Context) (*Claims, bool) {
 	claims, ok := ctx.Value(UserContextKey).(*Claims)
 	return claims, ok
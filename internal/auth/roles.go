@@ -0,0 +1,148 @@
+package auth
+
+import (
+	"net/http"
+
+	"github.com/Sea-Chels/go-practice-1/internal/utils"
+)
+
+type Role string
+
+const (
+	RoleAdmin    Role = "admin"
+	RoleTeacher  Role = "teacher"
+	RoleStaff    Role = "staff"
+	RoleReadOnly Role = "read_only"
+)
+
+type Permission string
+
+const (
+	PermStudentsRead    Permission = "students:read"
+	PermStudentsWrite   Permission = "students:write"
+	PermStudentsDelete  Permission = "students:delete"
+	PermStudentsPurge   Permission = "students:purge"
+	PermStudentsPromote Permission = "students:promote"
+	PermTeachersRead    Permission = "teachers:read"
+	PermTeachersWrite   Permission = "teachers:write"
+	PermTeachersDelete  Permission = "teachers:delete"
+	PermCoursesRead     Permission = "courses:read"
+	PermCoursesWrite    Permission = "courses:write"
+	PermCoursesDelete   Permission = "courses:delete"
+	PermCalendarRead    Permission = "calendar:read"
+	PermCalendarWrite   Permission = "calendar:write"
+	PermAttendanceRead  Permission = "attendance:read"
+	PermAttendanceWrite Permission = "attendance:write"
+	PermGradesRead      Permission = "grades:read"
+	PermGradesWrite     Permission = "grades:write"
+	PermUsersManage     Permission = "users:manage"
+	PermAuditRead       Permission = "audit:read"
+	PermAPIKeysManage   Permission = "api_keys:manage"
+)
+
+// AllPermissions lists every permission, which is also the set of scopes an
+// API key may be granted.
+var AllPermissions = []Permission{
+	PermStudentsRead, PermStudentsWrite, PermStudentsDelete, PermStudentsPurge, PermStudentsPromote,
+	PermTeachersRead, PermTeachersWrite, PermTeachersDelete,
+	PermCoursesRead, PermCoursesWrite, PermCoursesDelete,
+	PermCalendarRead, PermCalendarWrite,
+	PermAttendanceRead, PermAttendanceWrite,
+	PermGradesRead, PermGradesWrite,
+	PermUsersManage, PermAuditRead, PermAPIKeysManage,
+}
+
+func (p Permission) Valid() bool {
+	for _, known := range AllPermissions {
+		if p == known {
+			return true
+		}
+	}
+	return false
+}
+
+// rolePermissions lists what each non-admin role may do. Admins are allowed
+// everything.
+var rolePermissions = map[Role][]Permission{
+	RoleTeacher: {
+		PermStudentsRead, PermStudentsWrite,
+		PermTeachersRead,
+		PermCoursesRead,
+		PermCalendarRead,
+		PermAttendanceRead, PermAttendanceWrite,
+		PermGradesRead, PermGradesWrite,
+	},
+	RoleStaff: {
+		PermStudentsRead, PermStudentsWrite,
+		PermTeachersRead, PermTeachersWrite,
+		PermCoursesRead, PermCoursesWrite,
+		PermCalendarRead, PermCalendarWrite,
+		PermAttendanceRead, PermAttendanceWrite,
+		PermGradesRead, PermGradesWrite,
+	},
+	RoleReadOnly: {
+		PermStudentsRead,
+		PermTeachersRead,
+		PermCoursesRead,
+		PermCalendarRead,
+		PermAttendanceRead,
+		PermGradesRead,
+	},
+}
+
+func (r Role) Valid() bool {
+	switch r {
+	case RoleAdmin, RoleTeacher, RoleStaff, RoleReadOnly:
+		return true
+	}
+	return false
+}
+
+// Can reports whether the role grants the permission.
+func (r Role) Can(permission Permission) bool {
+	if r == RoleAdmin {
+		return true
+	}
+	for _, p := range rolePermissions[r] {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// Can reports whether the authenticated caller holds the permission, through
+// its role for users or for API keys through its scopes, limited to what the
+// creator's role still grants.
+func (c *Claims) Can(permission Permission) bool {
+	if c.APIKeyID != 0 {
+		// A key never grants more than its creator's current role allows.
+		for _, scope := range c.Scopes {
+			if Permission(scope) == permission {
+				return Role(c.Role).Can(permission)
+			}
+		}
+		return false
+	}
+	return Role(c.Role).Can(permission)
+}
+
+// RequirePermission rejects requests whose authenticated caller lacks the
+// permission. It must be wrapped by JWTMiddleware or Authenticate so the
+// claims are present.
+func RequirePermission(permission Permission, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := GetUserFromContext(r.Context())
+		if !ok {
+			utils.ErrorResponse(w, "Authentication required", http.StatusUnauthorized)
+			return
+		}
+
+		if !claims.Can(permission) {
+			utils.ErrorResponse(w, "Insufficient permissions", http.StatusForbidden)
+			return
+		}
+
+		next(w, r)
+	}
+}
@@ -0,0 +1,63 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultAccessTokenTTL  = 15 * time.Minute
+	defaultRefreshTokenTTL = 30 * 24 * time.Hour
+)
+
+// AccessTokenTTL returns the lifetime of access tokens, configurable through
+// JWT_ACCESS_TTL_MINUTES.
+func AccessTokenTTL() time.Duration {
+	return durationFromEnv("JWT_ACCESS_TTL_MINUTES", time.Minute, defaultAccessTokenTTL)
+}
+
+// RefreshTokenTTL returns the lifetime of refresh tokens, configurable through
+// JWT_REFRESH_TTL_HOURS.
+func RefreshTokenTTL() time.Duration {
+	return durationFromEnv("JWT_REFRESH_TTL_HOURS", time.Hour, defaultRefreshTokenTTL)
+}
+
+func durationFromEnv(key string, unit time.Duration, fallback time.Duration) time.Duration {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil || value <= 0 {
+		return fallback
+	}
+	return time.Duration(value) * unit
+}
+
+// GenerateOpaqueToken returns a random URL-safe token together with the hash
+// that should be persisted in its place.
+func GenerateOpaqueToken() (string, string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", fmt.Errorf("failed to generate token: %w", err)
+	}
+	token := base64.RawURLEncoding.EncodeToString(buf)
+	return token, HashToken(token), nil
+}
+
+// HashToken returns the hex encoded SHA-256 digest of an opaque token.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// NewTokenFamily returns a random identifier for a refresh token family.
+func NewTokenFamily() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate token family: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
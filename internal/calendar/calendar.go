@@ -0,0 +1,81 @@
+// Package calendar answers questions about the school calendar, such as which
+// term a date falls in, for any handler that needs a time frame.
+package calendar
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/Sea-Chels/go-practice-1/internal/models"
+)
+
+// ErrNoTerm is returned when no term covers the date.
+var ErrNoTerm = errors.New("no term covers the date")
+
+// Querier is satisfied by both *sql.DB and *sql.Tx.
+type Querier interface {
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+// Today returns the current date at midnight UTC, the form dates read from
+// DATE columns take.
+func Today() time.Time {
+	now := time.Now()
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// TermOn returns the term of the given type covering date. With an empty
+// termType the shortest term covering the date wins, so a quarter is chosen
+// over the semester containing it.
+func TermOn(db Querier, date time.Time, termType string) (*models.Term, error) {
+	var term models.Term
+	err := db.QueryRow(`
+		SELECT id, academic_year_id, name, type, start_date, end_date, created_at, updated_at
+		FROM terms
+		WHERE $1::date BETWEEN start_date AND end_date AND ($2 = '' OR type = $2)
+		ORDER BY end_date - start_date, start_date, id
+		LIMIT 1
+	`, date.Format("2006-01-02"), termType).Scan(&term.ID, &term.AcademicYearID, &term.Name, &term.Type,
+		&term.StartDate, &term.EndDate, &term.CreatedAt, &term.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, ErrNoTerm
+	} else if err != nil {
+		return nil, err
+	}
+	return &term, nil
+}
+
+// CurrentTerm returns the term covering today; see TermOn.
+func CurrentTerm(db Querier, termType string) (*models.Term, error) {
+	return TermOn(db, Today(), termType)
+}
+
+// IsInstructionalDay reports whether school is in session on date: a weekday
+// inside a term that is not covered by a holiday or non-instructional event.
+func IsInstructionalDay(db Querier, date time.Time) (bool, error) {
+	if date.Weekday() == time.Saturday || date.Weekday() == time.Sunday {
+		return false, nil
+	}
+
+	var instructional bool
+	err := db.QueryRow(`
+		SELECT EXISTS(SELECT 1 FROM terms WHERE $1::date BETWEEN start_date AND end_date)
+		   AND NOT EXISTS(SELECT 1 FROM calendar_events WHERE $1::date BETWEEN start_date AND end_date)
+	`, date.Format("2006-01-02")).Scan(&instructional)
+	return instructional, err
+}
+
+// InstructionalDays counts the instructional days from one date to another,
+// both included; see IsInstructionalDay.
+func InstructionalDays(db Querier, from, to time.Time) (int, error) {
+	var days int
+	err := db.QueryRow(`
+		SELECT COUNT(*)
+		FROM generate_series($1::date, $2::date, interval '1 day') AS d(day)
+		WHERE EXTRACT(ISODOW FROM d.day) < 6
+		  AND EXISTS(SELECT 1 FROM terms WHERE d.day::date BETWEEN start_date AND end_date)
+		  AND NOT EXISTS(SELECT 1 FROM calendar_events WHERE d.day::date BETWEEN start_date AND end_date)
+	`, from.Format("2006-01-02"), to.Format("2006-01-02")).Scan(&days)
+	return days, err
+}
@@ -29,9 +29,9 @@ func SeedDatabase() error {
 	}
 
 	_, err = DB.Exec(`
-		INSERT INTO users (email, password_hash, created_at, updated_at)
-		VALUES ($1, $2, $3, $4)
-	`, "admin@example.com", string(hashedPassword), time.Now(), time.Now())
+		INSERT INTO users (email, password_hash, role, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`, "admin@example.com", string(hashedPassword), "admin", time.Now(), time.Now())
 	if err != nil {
 		return fmt.Errorf("failed to insert admin user: %w", err)
 	}
@@ -40,23 +40,25 @@ func SeedDatabase() error {
 
 	// Seed students
 	students := []struct {
-		name  string
-		grade int
+		firstName string
+		lastName  string
+		grade     int
 	}{
-		{"Alice Johnson", 10},
-		{"Bob Smith", 11},
-		{"Charlie Brown", 9},
-		{"Diana Prince", 12},
-		{"Ethan Hunt", 10},
+		{"Alice", "Johnson", 10},
+		{"Bob", "Smith", 11},
+		{"Charlie", "Brown", 9},
+		{"Diana", "Prince", 12},
+		{"Ethan", "Hunt", 10},
 	}
 
 	for _, student := range students {
+		name := student.firstName + " " + student.lastName
 		_, err := DB.Exec(`
-			INSERT INTO students (name, grade, created_at, updated_at)
-			VALUES ($1, $2, $3, $4)
-		`, student.name, student.grade, time.Now(), time.Now())
+			INSERT INTO students (name, first_name, last_name, grade, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6)
+		`, name, student.firstName, student.lastName, student.grade, time.Now(), time.Now())
 		if err != nil {
-			return fmt.Errorf("failed to insert student %s: %w", student.name, err)
+			return fmt.Errorf("failed to insert student %s: %w", name, err)
 		}
 	}
 
@@ -0,0 +1,45 @@
+package export
+
+import (
+	"encoding/csv"
+	"io"
+	"strings"
+)
+
+type csvWriter struct {
+	w *csv.Writer
+}
+
+func newCSVWriter(w io.Writer, columns []string) (*csvWriter, error) {
+	writer := &csvWriter{w: csv.NewWriter(w)}
+	if err := writer.w.Write(columns); err != nil {
+		return nil, err
+	}
+	return writer, nil
+}
+
+func (c *csvWriter) WriteRow(values []interface{}) error {
+	record := make([]string, len(values))
+	for i, value := range values {
+		text := formatValue(value)
+		if _, ok := deref(value).(string); ok {
+			text = escapeFormula(text)
+		}
+		record[i] = text
+	}
+	return c.w.Write(record)
+}
+
+func (c *csvWriter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// escapeFormula stops spreadsheet applications from evaluating text that
+// looks like a formula when the CSV is opened.
+func escapeFormula(text string) string {
+	if text != "" && strings.ContainsRune("=+-@\t\r", rune(text[0])) {
+		return "'" + text
+	}
+	return text
+}
@@ -0,0 +1,124 @@
+// Package export writes tabular data as CSV, JSON Lines or XLSX one row at a
+// time, so large result sets never have to be held in memory.
+package export
+
+import (
+	"fmt"
+	"io"
+	"mime"
+	"strings"
+	"time"
+)
+
+type Format string
+
+const (
+	FormatCSV    Format = "csv"
+	FormatNDJSON Format = "ndjson"
+	FormatXLSX   Format = "xlsx"
+)
+
+var contentTypes = map[Format]string{
+	FormatCSV:    "text/csv; charset=utf-8",
+	FormatNDJSON: "application/x-ndjson",
+	FormatXLSX:   "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+}
+
+// mediaTypes maps the media types accepted in an Accept header to a format.
+var mediaTypes = map[string]Format{
+	"text/csv":             FormatCSV,
+	"application/x-ndjson": FormatNDJSON,
+	"application/jsonl":    FormatNDJSON,
+	"application/json-seq": FormatNDJSON,
+	"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet": FormatXLSX,
+}
+
+func (f Format) ContentType() string {
+	return contentTypes[f]
+}
+
+// Negotiate picks the output format. An explicit format parameter wins;
+// otherwise the first supported type in the Accept header is used, and CSV
+// when the client accepts anything.
+func Negotiate(format, accept string) (Format, error) {
+	if format != "" {
+		if _, ok := contentTypes[Format(format)]; !ok {
+			return "", fmt.Errorf("format must be one of csv, ndjson, xlsx")
+		}
+		return Format(format), nil
+	}
+
+	if strings.TrimSpace(accept) == "" {
+		return FormatCSV, nil
+	}
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		if f, ok := mediaTypes[mediaType]; ok {
+			return f, nil
+		}
+		if mediaType == "*/*" || mediaType == "text/*" {
+			return FormatCSV, nil
+		}
+	}
+	return "", fmt.Errorf("the Accept header must allow text/csv, application/x-ndjson or an XLSX spreadsheet")
+}
+
+// Writer writes rows whose values line up with the columns it was created
+// with. Values may be nil, strings, integers, floats, bools, times or
+// pointers to those.
+type Writer interface {
+	WriteRow(values []interface{}) error
+	// Close flushes buffered output. It does not close the underlying writer.
+	Close() error
+}
+
+func NewWriter(format Format, w io.Writer, columns []string) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVWriter(w, columns)
+	case FormatNDJSON:
+		return newNDJSONWriter(w, columns), nil
+	case FormatXLSX:
+		return newXLSXWriter(w, columns)
+	}
+	return nil, fmt.Errorf("unsupported export format %q", format)
+}
+
+// deref unwraps pointer values, returning nil for nil pointers.
+func deref(value interface{}) interface{} {
+	switch v := value.(type) {
+	case *string:
+		if v == nil {
+			return nil
+		}
+		return *v
+	case *int:
+		if v == nil {
+			return nil
+		}
+		return *v
+	case *time.Time:
+		if v == nil {
+			return nil
+		}
+		return *v
+	}
+	return value
+}
+
+// formatValue renders a value as text for formats without native types.
+func formatValue(value interface{}) string {
+	switch v := deref(value).(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case time.Time:
+		return v.Format(time.RFC3339)
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,43 @@
+package export
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+)
+
+// ndjsonWriter writes one JSON object per line with keys in column order.
+type ndjsonWriter struct {
+	w    *bufio.Writer
+	keys [][]byte
+}
+
+func newNDJSONWriter(w io.Writer, columns []string) *ndjsonWriter {
+	keys := make([][]byte, len(columns))
+	for i, column := range columns {
+		keys[i], _ = json.Marshal(column)
+	}
+	return &ndjsonWriter{w: bufio.NewWriter(w), keys: keys}
+}
+
+func (n *ndjsonWriter) WriteRow(values []interface{}) error {
+	n.w.WriteByte('{')
+	for i, value := range values {
+		if i > 0 {
+			n.w.WriteByte(',')
+		}
+		encoded, err := json.Marshal(deref(value))
+		if err != nil {
+			return err
+		}
+		n.w.Write(n.keys[i])
+		n.w.WriteByte(':')
+		n.w.Write(encoded)
+	}
+	_, err := n.w.WriteString("}\n")
+	return err
+}
+
+func (n *ndjsonWriter) Close() error {
+	return n.w.Flush()
+}
@@ -0,0 +1,206 @@
+// Package gradebook computes running averages and letter grades from the
+// categories, assignments and scores of a section.
+package gradebook
+
+import (
+	"database/sql"
+	"encoding/json"
+	"math"
+
+	"github.com/Sea-Chels/go-practice-1/internal/models"
+)
+
+// ScaleColumns are read by ScanScale.
+const ScaleColumns = `id, name, is_default, bands, created_at, updated_at`
+
+// Querier is satisfied by both *sql.DB and *sql.Tx.
+type Querier interface {
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+// ScanScale reads ScaleColumns, decoding the bands.
+func ScanScale(row interface{ Scan(...interface{}) error }, scale *models.GradingScale) error {
+	var bands []byte
+	if err := row.Scan(&scale.ID, &scale.Name, &scale.IsDefault, &bands, &scale.CreatedAt, &scale.UpdatedAt); err != nil {
+		return err
+	}
+	return json.Unmarshal(bands, &scale.Bands)
+}
+
+// Letter returns the letter of the highest band percent reaches, or nil
+// without a scale.
+func Letter(scale *models.GradingScale, percent float64) *string {
+	if scale == nil {
+		return nil
+	}
+	for _, band := range scale.Bands {
+		if percent >= band.MinPercent {
+			letter := band.Letter
+			return &letter
+		}
+	}
+	return nil
+}
+
+type assignment struct {
+	id         int
+	categoryID int
+	maxPoints  float64
+}
+
+type mark struct {
+	points  *float64
+	missing bool
+	exempt  bool
+}
+
+// Book holds everything needed to grade the students of one section.
+type Book struct {
+	SectionID int
+	// Scale is the section's grading scale, or the default one when the
+	// section has none; nil if neither exists.
+	Scale       *models.GradingScale
+	Categories  []models.GradeCategory
+	assignments []assignment
+	// marks is indexed by student, then assignment.
+	marks map[int]map[int]mark
+}
+
+// Load reads the gradebook of a section.
+func Load(db Querier, sectionID int) (*Book, error) {
+	book := &Book{SectionID: sectionID, marks: map[int]map[int]mark{}}
+
+	var scale models.GradingScale
+	err := ScanScale(db.QueryRow(`
+		SELECT `+ScaleColumns+` FROM grading_scales
+		WHERE id = COALESCE((SELECT grading_scale_id FROM sections WHERE id = $1),
+		                    (SELECT id FROM grading_scales WHERE is_default))
+	`, sectionID), &scale)
+	if err == nil {
+		book.Scale = &scale
+	} else if err != sql.ErrNoRows {
+		return nil, err
+	}
+
+	rows, err := db.Query(`
+		SELECT id, section_id, name, weight, created_at, updated_at
+		FROM grade_categories WHERE section_id = $1 ORDER BY id
+	`, sectionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var category models.GradeCategory
+		if err := rows.Scan(&category.ID, &category.SectionID, &category.Name, &category.Weight,
+			&category.CreatedAt, &category.UpdatedAt); err != nil {
+			return nil, err
+		}
+		book.Categories = append(book.Categories, category)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = db.Query(`SELECT id, category_id, max_points FROM assignments WHERE section_id = $1`, sectionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var a assignment
+		if err := rows.Scan(&a.id, &a.categoryID, &a.maxPoints); err != nil {
+			return nil, err
+		}
+		book.assignments = append(book.assignments, a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = db.Query(`
+		SELECT sc.student_id, sc.assignment_id, sc.points, sc.missing, sc.exempt
+		FROM scores sc
+		JOIN assignments a ON a.id = sc.assignment_id
+		WHERE a.section_id = $1
+	`, sectionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var studentID, assignmentID int
+		var m mark
+		if err := rows.Scan(&studentID, &assignmentID, &m.points, &m.missing, &m.exempt); err != nil {
+			return nil, err
+		}
+		if book.marks[studentID] == nil {
+			book.marks[studentID] = map[int]mark{}
+		}
+		book.marks[studentID][assignmentID] = m
+	}
+	return book, rows.Err()
+}
+
+// Grade computes a student's running average. Only graded work counts:
+// scored assignments, and missing ones as zero; exempt and unscored
+// assignments are left out. Each category averages its points, and the
+// categories are weighted against the others that have graded work.
+func (b *Book) Grade(studentID int) models.StudentGrade {
+	type total struct {
+		earned, possible float64
+		graded           int
+	}
+	totals := map[int]*total{}
+	for _, a := range b.assignments {
+		m, ok := b.marks[studentID][a.id]
+		if !ok || m.exempt {
+			continue
+		}
+		var points float64
+		switch {
+		case m.missing:
+			points = 0
+		case m.points != nil:
+			points = *m.points
+		default:
+			continue
+		}
+		t := totals[a.categoryID]
+		if t == nil {
+			t = &total{}
+			totals[a.categoryID] = t
+		}
+		t.earned += points
+		t.possible += a.maxPoints
+		t.graded++
+	}
+
+	grade := models.StudentGrade{StudentID: studentID, SectionID: b.SectionID, Categories: []models.CategoryGrade{}}
+	var weighted, weights float64
+	for _, category := range b.Categories {
+		categoryGrade := models.CategoryGrade{CategoryID: category.ID, Name: category.Name, Weight: category.Weight}
+		if t := totals[category.ID]; t != nil {
+			percent := t.earned / t.possible * 100
+			rounded := round(percent)
+			categoryGrade.Percent = &rounded
+			categoryGrade.Graded = t.graded
+			weighted += category.Weight * percent
+			weights += category.Weight
+		}
+		grade.Categories = append(grade.Categories, categoryGrade)
+	}
+
+	if weights > 0 {
+		percent := round(weighted / weights)
+		grade.Percent = &percent
+		grade.Letter = Letter(b.Scale, percent)
+	}
+	return grade
+}
+
+// round keeps two decimals.
+func round(value float64) float64 {
+	return math.Round(value*100) / 100
+}
@@ -0,0 +1,842 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Sea-Chels/go-practice-1/internal/calendar"
+	"github.com/Sea-Chels/go-practice-1/internal/database"
+	"github.com/Sea-Chels/go-practice-1/internal/models"
+	"github.com/Sea-Chels/go-practice-1/internal/utils"
+)
+
+const academicYearColumns = `id, name, start_date, end_date, created_at, updated_at`
+
+const termColumns = `id, academic_year_id, name, type, start_date, end_date, created_at, updated_at`
+
+const calendarEventColumns = `id, academic_year_id, name, type, start_date, end_date, created_at, updated_at`
+
+func scanAcademicYear(row interface{ Scan(...interface{}) error }, year *models.AcademicYear) error {
+	return row.Scan(&year.ID, &year.Name, &year.StartDate, &year.EndDate, &year.CreatedAt, &year.UpdatedAt)
+}
+
+func scanTerm(row interface{ Scan(...interface{}) error }, term *models.Term) error {
+	return row.Scan(&term.ID, &term.AcademicYearID, &term.Name, &term.Type, &term.StartDate, &term.EndDate,
+		&term.CreatedAt, &term.UpdatedAt)
+}
+
+func scanCalendarEvent(row interface{ Scan(...interface{}) error }, event *models.CalendarEvent) error {
+	return row.Scan(&event.ID, &event.AcademicYearID, &event.Name, &event.Type, &event.StartDate, &event.EndDate,
+		&event.CreatedAt, &event.UpdatedAt)
+}
+
+// parseDateRange parses the required start_date and end_date of a calendar
+// entry. Both days are included; sameDay allows them to be equal.
+func parseDateRange(startValue, endValue string, sameDay bool) (time.Time, time.Time, error) {
+	start, err := utils.ParseDate("start_date", startValue)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	end, err := utils.ParseDate("end_date", endValue)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	if start == nil || end == nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("start_date and end_date are required")
+	}
+	if end.Before(*start) || (!sameDay && end.Equal(*start)) {
+		return time.Time{}, time.Time{}, fmt.Errorf("end_date must be after start_date")
+	}
+	return *start, *end, nil
+}
+
+func formatDate(date time.Time) string {
+	return date.Format("2006-01-02")
+}
+
+// lockAcademicYear locks a year for the transaction, so its terms and events
+// are checked against dates that cannot change meanwhile. It returns a client
+// error message when the year does not exist.
+func lockAcademicYear(tx *sql.Tx, yearID int, start, end time.Time) (string, error) {
+	var yearStart, yearEnd time.Time
+	err := tx.QueryRow(`
+		SELECT start_date, end_date FROM academic_years WHERE id = $1 FOR UPDATE
+	`, yearID).Scan(&yearStart, &yearEnd)
+	if err == sql.ErrNoRows {
+		return "academic_year_id does not refer to an existing academic year", nil
+	} else if err != nil {
+		return "", err
+	}
+
+	if start.Before(yearStart) || end.After(yearEnd) {
+		return fmt.Sprintf("start_date and end_date must lie within the academic year (%s to %s)",
+			formatDate(yearStart), formatDate(yearEnd)), nil
+	}
+	return "", nil
+}
+
+func GetAcademicYearsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.ErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	rows, err := database.DB.Query(`SELECT ` + academicYearColumns + ` FROM academic_years ORDER BY start_date`)
+	if err != nil {
+		log.Printf("GetAcademicYearsHandler: query error: %v", err)
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	years := []models.AcademicYear{}
+	for rows.Next() {
+		var year models.AcademicYear
+		if err := scanAcademicYear(rows, &year); err != nil {
+			utils.ErrorResponse(w, "Error scanning results", http.StatusInternalServerError)
+			return
+		}
+		years = append(years, year)
+	}
+
+	if err = rows.Err(); err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	utils.SuccessResponse(w, models.AcademicYearsResponse{AcademicYears: years, Count: len(years)}, http.StatusOK)
+}
+
+// GetAcademicYearHandler returns a year with its terms and calendar events.
+func GetAcademicYearHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.ErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	yearID, err := parseIDParam(r, "id")
+	if err != nil {
+		utils.ErrorResponse(w, "Invalid academic year ID", http.StatusBadRequest)
+		return
+	}
+
+	var year models.AcademicYear
+	err = scanAcademicYear(database.DB.QueryRow(`
+		SELECT `+academicYearColumns+` FROM academic_years WHERE id = $1
+	`, yearID), &year)
+	if err == sql.ErrNoRows {
+		utils.ErrorResponse(w, "Academic year not found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	if year.Terms, err = queryTerms(`WHERE academic_year_id = $1`, yearID); err != nil {
+		log.Printf("GetAcademicYearHandler: error=%v", err)
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	if year.Events, err = queryCalendarEvents(`WHERE academic_year_id = $1`, yearID); err != nil {
+		log.Printf("GetAcademicYearHandler: error=%v", err)
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	utils.SuccessResponse(w, year, http.StatusOK)
+}
+
+func validateAcademicYearRequest(req *models.CreateAcademicYearRequest) (time.Time, time.Time, error) {
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		return time.Time{}, time.Time{}, fmt.Errorf("name is required")
+	}
+	if err := utils.ValidateMaxLength("name", req.Name, 50); err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	return parseDateRange(req.StartDate, req.EndDate, false)
+}
+
+func CreateAcademicYearHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.ErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var createReq models.CreateAcademicYearRequest
+	if err := json.NewDecoder(r.Body).Decode(&createReq); err != nil {
+		utils.ErrorResponse(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	start, end, err := validateAcademicYearRequest(&createReq)
+	if err != nil {
+		utils.ErrorResponse(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	writeAcademicYear(w, 0, createReq, start, end)
+}
+
+// UpdateAcademicYearHandler changes a year. Its terms and events must still
+// lie within the new dates.
+func UpdateAcademicYearHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		utils.ErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	yearID, err := parseIDParam(r, "id")
+	if err != nil {
+		utils.ErrorResponse(w, "Invalid academic year ID", http.StatusBadRequest)
+		return
+	}
+
+	var updateReq models.CreateAcademicYearRequest
+	if err := json.NewDecoder(r.Body).Decode(&updateReq); err != nil {
+		utils.ErrorResponse(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	start, end, err := validateAcademicYearRequest(&updateReq)
+	if err != nil {
+		utils.ErrorResponse(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	writeAcademicYear(w, yearID, updateReq, start, end)
+}
+
+// writeAcademicYear creates the year when yearID is 0 and updates it
+// otherwise. The table is locked so concurrent writes cannot both pass the
+// overlap check.
+func writeAcademicYear(w http.ResponseWriter, yearID int, req models.CreateAcademicYearRequest, start, end time.Time) {
+	tx, err := database.DB.Begin()
+	if err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`LOCK TABLE academic_years IN SHARE ROW EXCLUSIVE MODE`); err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	var overlapping string
+	err = tx.QueryRow(`
+		SELECT name FROM academic_years
+		WHERE id <> $1 AND start_date <= $3 AND end_date >= $2
+		ORDER BY start_date LIMIT 1
+	`, yearID, start, end).Scan(&overlapping)
+	if err == nil {
+		utils.ErrorResponse(w, fmt.Sprintf("The dates overlap academic year %s", overlapping), http.StatusConflict)
+		return
+	} else if err != sql.ErrNoRows {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	var year models.AcademicYear
+	status := http.StatusOK
+	if yearID == 0 {
+		status = http.StatusCreated
+		err = scanAcademicYear(tx.QueryRow(`
+			INSERT INTO academic_years (name, start_date, end_date, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $4)
+			RETURNING `+academicYearColumns,
+			req.Name, start, end, time.Now()), &year)
+	} else {
+		err = scanAcademicYear(tx.QueryRow(`
+			UPDATE academic_years
+			SET name = $2, start_date = $3, end_date = $4, updated_at = $5
+			WHERE id = $1
+			RETURNING `+academicYearColumns,
+			yearID, req.Name, start, end, time.Now()), &year)
+	}
+
+	if err == sql.ErrNoRows {
+		utils.ErrorResponse(w, "Academic year not found", http.StatusNotFound)
+		return
+	} else if isUniqueViolation(err) {
+		utils.ErrorResponse(w, "An academic year with this name already exists", http.StatusConflict)
+		return
+	} else if err != nil {
+		log.Printf("writeAcademicYear: error=%v", err)
+		utils.ErrorResponse(w, "Failed to save academic year", http.StatusInternalServerError)
+		return
+	}
+
+	var outside bool
+	if err := tx.QueryRow(`
+		SELECT EXISTS(SELECT 1 FROM terms WHERE academic_year_id = $1 AND (start_date < $2 OR end_date > $3))
+		    OR EXISTS(SELECT 1 FROM calendar_events WHERE academic_year_id = $1 AND (start_date < $2 OR end_date > $3))
+	`, year.ID, start, end).Scan(&outside); err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	if outside {
+		utils.ErrorResponse(w, "Some terms or events of the year fall outside the new dates", http.StatusConflict)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	utils.SuccessResponse(w, year, status)
+}
+
+// DeleteAcademicYearHandler deletes a year with its terms and events, unless
+// sections are scheduled in one of its terms.
+func DeleteAcademicYearHandler(w http.ResponseWriter, r *http.Request) {
+	yearID, err := parseIDParam(r, "id")
+	if err != nil {
+		utils.ErrorResponse(w, "Invalid academic year ID", http.StatusBadRequest)
+		return
+	}
+
+	var inUse bool
+	if err := database.DB.QueryRow(`
+		SELECT EXISTS(SELECT 1 FROM sections s JOIN terms t ON t.id = s.term_id WHERE t.academic_year_id = $1)
+	`, yearID).Scan(&inUse); err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	if inUse {
+		utils.ErrorResponse(w, "Sections are scheduled in terms of this academic year", http.StatusConflict)
+		return
+	}
+
+	result, err := database.DB.Exec(`DELETE FROM academic_years WHERE id = $1`, yearID)
+	if err != nil {
+		log.Printf("DeleteAcademicYearHandler: error=%v", err)
+		utils.ErrorResponse(w, "Failed to delete academic year", http.StatusInternalServerError)
+		return
+	}
+
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		utils.ErrorResponse(w, "Academic year not found", http.StatusNotFound)
+		return
+	}
+
+	utils.SuccessResponse(w, nil, http.StatusNoContent)
+}
+
+func queryTerms(where string, args ...interface{}) ([]models.Term, error) {
+	rows, err := database.DB.Query(`SELECT `+termColumns+` FROM terms `+where+` ORDER BY start_date, end_date DESC, id`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	terms := []models.Term{}
+	for rows.Next() {
+		var term models.Term
+		if err := scanTerm(rows, &term); err != nil {
+			return nil, err
+		}
+		terms = append(terms, term)
+	}
+	return terms, rows.Err()
+}
+
+// GetTermsHandler lists terms, optionally filtered by academic_year_id and
+// type.
+func GetTermsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.ErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+	var conditions []string
+	var args []interface{}
+	if value := query.Get("academic_year_id"); value != "" {
+		yearID, err := strconv.Atoi(value)
+		if err != nil || yearID <= 0 {
+			utils.ErrorResponse(w, "academic_year_id must be a positive integer", http.StatusBadRequest)
+			return
+		}
+		args = append(args, yearID)
+		conditions = append(conditions, fmt.Sprintf("academic_year_id = $%d", len(args)))
+	}
+	if termType := query.Get("type"); termType != "" {
+		if err := utils.ValidateTermType(termType); err != nil {
+			utils.ErrorResponse(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		args = append(args, termType)
+		conditions = append(conditions, fmt.Sprintf("type = $%d", len(args)))
+	}
+
+	terms, err := queryTerms(whereClause(conditions), args...)
+	if err != nil {
+		log.Printf("GetTermsHandler: error=%v", err)
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	utils.SuccessResponse(w, models.TermsResponse{Terms: terms, Count: len(terms)}, http.StatusOK)
+}
+
+func GetTermHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.ErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	termID, err := parseIDParam(r, "id")
+	if err != nil {
+		utils.ErrorResponse(w, "Invalid term ID", http.StatusBadRequest)
+		return
+	}
+
+	var term models.Term
+	err = scanTerm(database.DB.QueryRow(`SELECT `+termColumns+` FROM terms WHERE id = $1`, termID), &term)
+	if err == sql.ErrNoRows {
+		utils.ErrorResponse(w, "Term not found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	utils.SuccessResponse(w, term, http.StatusOK)
+}
+
+// GetCurrentTermHandler returns the term covering today, or the date
+// parameter, optionally limited to one type.
+func GetCurrentTermHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.ErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+	date := calendar.Today()
+	if parsed, err := utils.ParseDate("date", query.Get("date")); err != nil {
+		utils.ErrorResponse(w, err.Error(), http.StatusBadRequest)
+		return
+	} else if parsed != nil {
+		date = *parsed
+	}
+	termType := query.Get("type")
+	if termType != "" {
+		if err := utils.ValidateTermType(termType); err != nil {
+			utils.ErrorResponse(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	term, err := calendar.TermOn(database.DB, date, termType)
+	if errors.Is(err, calendar.ErrNoTerm) {
+		utils.ErrorResponse(w, fmt.Sprintf("No term covers %s", formatDate(date)), http.StatusNotFound)
+		return
+	} else if err != nil {
+		log.Printf("GetCurrentTermHandler: error=%v", err)
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	utils.SuccessResponse(w, term, http.StatusOK)
+}
+
+func validateTermRequest(req *models.CreateTermRequest) (time.Time, time.Time, error) {
+	req.Name = strings.TrimSpace(req.Name)
+	req.Type = strings.TrimSpace(req.Type)
+
+	if req.AcademicYearID <= 0 {
+		return time.Time{}, time.Time{}, fmt.Errorf("academic_year_id is required")
+	}
+	if req.Name == "" {
+		return time.Time{}, time.Time{}, fmt.Errorf("name is required")
+	}
+	if err := utils.ValidateMaxLength("name", req.Name, 50); err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	if err := utils.ValidateTermType(req.Type); err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	return parseDateRange(req.StartDate, req.EndDate, false)
+}
+
+func CreateTermHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.ErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var createReq models.CreateTermRequest
+	if err := json.NewDecoder(r.Body).Decode(&createReq); err != nil {
+		utils.ErrorResponse(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	start, end, err := validateTermRequest(&createReq)
+	if err != nil {
+		utils.ErrorResponse(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	writeTerm(w, 0, createReq, start, end)
+}
+
+func UpdateTermHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		utils.ErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	termID, err := parseIDParam(r, "id")
+	if err != nil {
+		utils.ErrorResponse(w, "Invalid term ID", http.StatusBadRequest)
+		return
+	}
+
+	var updateReq models.CreateTermRequest
+	if err := json.NewDecoder(r.Body).Decode(&updateReq); err != nil {
+		utils.ErrorResponse(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	start, end, err := validateTermRequest(&updateReq)
+	if err != nil {
+		utils.ErrorResponse(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	writeTerm(w, termID, updateReq, start, end)
+}
+
+// writeTerm creates the term when termID is 0 and updates it otherwise. A
+// term must lie within its academic year and must not overlap another term
+// of the same type; quarters may overlap the semester containing them.
+func writeTerm(w http.ResponseWriter, termID int, req models.CreateTermRequest, start, end time.Time) {
+	tx, err := database.DB.Begin()
+	if err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	if message, err := lockAcademicYear(tx, req.AcademicYearID, start, end); err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	} else if message != "" {
+		utils.ErrorResponse(w, message, http.StatusBadRequest)
+		return
+	}
+
+	var overlapping string
+	err = tx.QueryRow(`
+		SELECT name FROM terms
+		WHERE id <> $1 AND academic_year_id = $2 AND type = $3 AND start_date <= $5 AND end_date >= $4
+		ORDER BY start_date LIMIT 1
+	`, termID, req.AcademicYearID, req.Type, start, end).Scan(&overlapping)
+	if err == nil {
+		utils.ErrorResponse(w, fmt.Sprintf("The dates overlap the %s %s", req.Type, overlapping), http.StatusConflict)
+		return
+	} else if err != sql.ErrNoRows {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	var term models.Term
+	status := http.StatusOK
+	if termID == 0 {
+		status = http.StatusCreated
+		err = scanTerm(tx.QueryRow(`
+			INSERT INTO terms (academic_year_id, name, type, start_date, end_date, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $6)
+			RETURNING `+termColumns,
+			req.AcademicYearID, req.Name, req.Type, start, end, time.Now()), &term)
+	} else {
+		err = scanTerm(tx.QueryRow(`
+			UPDATE terms
+			SET academic_year_id = $2, name = $3, type = $4, start_date = $5, end_date = $6, updated_at = $7
+			WHERE id = $1
+			RETURNING `+termColumns,
+			termID, req.AcademicYearID, req.Name, req.Type, start, end, time.Now()), &term)
+	}
+
+	if err == sql.ErrNoRows {
+		utils.ErrorResponse(w, "Term not found", http.StatusNotFound)
+		return
+	} else if isUniqueViolation(err) {
+		utils.ErrorResponse(w, "The academic year already has a term with this name", http.StatusConflict)
+		return
+	} else if err != nil {
+		log.Printf("writeTerm: error=%v", err)
+		utils.ErrorResponse(w, "Failed to save term", http.StatusInternalServerError)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	utils.SuccessResponse(w, term, status)
+}
+
+// DeleteTermHandler deletes a term that no section is scheduled in.
+func DeleteTermHandler(w http.ResponseWriter, r *http.Request) {
+	termID, err := parseIDParam(r, "id")
+	if err != nil {
+		utils.ErrorResponse(w, "Invalid term ID", http.StatusBadRequest)
+		return
+	}
+
+	var inUse bool
+	if err := database.DB.QueryRow(`SELECT EXISTS(SELECT 1 FROM sections WHERE term_id = $1)`, termID).Scan(&inUse); err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	if inUse {
+		utils.ErrorResponse(w, "Sections are scheduled in this term", http.StatusConflict)
+		return
+	}
+
+	result, err := database.DB.Exec(`DELETE FROM terms WHERE id = $1`, termID)
+	if err != nil {
+		log.Printf("DeleteTermHandler: error=%v", err)
+		utils.ErrorResponse(w, "Failed to delete term", http.StatusInternalServerError)
+		return
+	}
+
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		utils.ErrorResponse(w, "Term not found", http.StatusNotFound)
+		return
+	}
+
+	utils.SuccessResponse(w, nil, http.StatusNoContent)
+}
+
+func queryCalendarEvents(where string, args ...interface{}) ([]models.CalendarEvent, error) {
+	rows, err := database.DB.Query(`SELECT `+calendarEventColumns+` FROM calendar_events `+where+` ORDER BY start_date, id`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []models.CalendarEvent{}
+	for rows.Next() {
+		var event models.CalendarEvent
+		if err := scanCalendarEvent(rows, &event); err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	return events, rows.Err()
+}
+
+// GetCalendarEventsHandler lists holidays and non-instructional days,
+// optionally filtered by academic_year_id, type and the from/to date range.
+func GetCalendarEventsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.ErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+	var conditions []string
+	var args []interface{}
+	if value := query.Get("academic_year_id"); value != "" {
+		yearID, err := strconv.Atoi(value)
+		if err != nil || yearID <= 0 {
+			utils.ErrorResponse(w, "academic_year_id must be a positive integer", http.StatusBadRequest)
+			return
+		}
+		args = append(args, yearID)
+		conditions = append(conditions, fmt.Sprintf("academic_year_id = $%d", len(args)))
+	}
+	if eventType := query.Get("type"); eventType != "" {
+		if err := utils.ValidateCalendarEventType(eventType); err != nil {
+			utils.ErrorResponse(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		args = append(args, eventType)
+		conditions = append(conditions, fmt.Sprintf("type = $%d", len(args)))
+	}
+	from, err := utils.ParseDate("from", query.Get("from"))
+	if err != nil {
+		utils.ErrorResponse(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if from != nil {
+		args = append(args, *from)
+		conditions = append(conditions, fmt.Sprintf("end_date >= $%d", len(args)))
+	}
+	to, err := utils.ParseDate("to", query.Get("to"))
+	if err != nil {
+		utils.ErrorResponse(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if to != nil {
+		args = append(args, *to)
+		conditions = append(conditions, fmt.Sprintf("start_date <= $%d", len(args)))
+	}
+
+	events, err := queryCalendarEvents(whereClause(conditions), args...)
+	if err != nil {
+		log.Printf("GetCalendarEventsHandler: error=%v", err)
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	utils.SuccessResponse(w, models.CalendarEventsResponse{Events: events, Count: len(events)}, http.StatusOK)
+}
+
+func validateCalendarEventRequest(req *models.CreateCalendarEventRequest) (time.Time, time.Time, error) {
+	req.Name = strings.TrimSpace(req.Name)
+	req.Type = strings.TrimSpace(req.Type)
+	if strings.TrimSpace(req.EndDate) == "" {
+		req.EndDate = req.StartDate
+	}
+
+	if req.AcademicYearID <= 0 {
+		return time.Time{}, time.Time{}, fmt.Errorf("academic_year_id is required")
+	}
+	if req.Name == "" {
+		return time.Time{}, time.Time{}, fmt.Errorf("name is required")
+	}
+	if err := utils.ValidateMaxLength("name", req.Name, 255); err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	if err := utils.ValidateCalendarEventType(req.Type); err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	return parseDateRange(req.StartDate, req.EndDate, true)
+}
+
+func CreateCalendarEventHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.ErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var createReq models.CreateCalendarEventRequest
+	if err := json.NewDecoder(r.Body).Decode(&createReq); err != nil {
+		utils.ErrorResponse(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	start, end, err := validateCalendarEventRequest(&createReq)
+	if err != nil {
+		utils.ErrorResponse(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	writeCalendarEvent(w, 0, createReq, start, end)
+}
+
+func UpdateCalendarEventHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		utils.ErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	eventID, err := parseIDParam(r, "id")
+	if err != nil {
+		utils.ErrorResponse(w, "Invalid calendar event ID", http.StatusBadRequest)
+		return
+	}
+
+	var updateReq models.CreateCalendarEventRequest
+	if err := json.NewDecoder(r.Body).Decode(&updateReq); err != nil {
+		utils.ErrorResponse(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	start, end, err := validateCalendarEventRequest(&updateReq)
+	if err != nil {
+		utils.ErrorResponse(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	writeCalendarEvent(w, eventID, updateReq, start, end)
+}
+
+// writeCalendarEvent creates the event when eventID is 0 and updates it
+// otherwise. Events must lie within their academic year.
+func writeCalendarEvent(w http.ResponseWriter, eventID int, req models.CreateCalendarEventRequest, start, end time.Time) {
+	tx, err := database.DB.Begin()
+	if err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	if message, err := lockAcademicYear(tx, req.AcademicYearID, start, end); err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	} else if message != "" {
+		utils.ErrorResponse(w, message, http.StatusBadRequest)
+		return
+	}
+
+	var event models.CalendarEvent
+	status := http.StatusOK
+	if eventID == 0 {
+		status = http.StatusCreated
+		err = scanCalendarEvent(tx.QueryRow(`
+			INSERT INTO calendar_events (academic_year_id, name, type, start_date, end_date, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $6)
+			RETURNING `+calendarEventColumns,
+			req.AcademicYearID, req.Name, req.Type, start, end, time.Now()), &event)
+	} else {
+		err = scanCalendarEvent(tx.QueryRow(`
+			UPDATE calendar_events
+			SET academic_year_id = $2, name = $3, type = $4, start_date = $5, end_date = $6, updated_at = $7
+			WHERE id = $1
+			RETURNING `+calendarEventColumns,
+			eventID, req.AcademicYearID, req.Name, req.Type, start, end, time.Now()), &event)
+	}
+
+	if err == sql.ErrNoRows {
+		utils.ErrorResponse(w, "Calendar event not found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		log.Printf("writeCalendarEvent: error=%v", err)
+		utils.ErrorResponse(w, "Failed to save calendar event", http.StatusInternalServerError)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	utils.SuccessResponse(w, event, status)
+}
+
+func DeleteCalendarEventHandler(w http.ResponseWriter, r *http.Request) {
+	eventID, err := parseIDParam(r, "id")
+	if err != nil {
+		utils.ErrorResponse(w, "Invalid calendar event ID", http.StatusBadRequest)
+		return
+	}
+
+	result, err := database.DB.Exec(`DELETE FROM calendar_events WHERE id = $1`, eventID)
+	if err != nil {
+		log.Printf("DeleteCalendarEventHandler: error=%v", err)
+		utils.ErrorResponse(w, "Failed to delete calendar event", http.StatusInternalServerError)
+		return
+	}
+
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		utils.ErrorResponse(w, "Calendar event not found", http.StatusNotFound)
+		return
+	}
+
+	utils.SuccessResponse(w, nil, http.StatusNoContent)
+}
@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Sea-Chels/go-practice-1/internal/auth"
+	"github.com/Sea-Chels/go-practice-1/internal/database"
+	"github.com/Sea-Chels/go-practice-1/internal/models"
+	"github.com/Sea-Chels/go-practice-1/internal/utils"
+	"github.com/lib/pq"
+)
+
+const apiKeyColumns = `id, name, prefix, scopes, created_by, expires_at, last_used_at, revoked_at, created_at`
+
+func scanAPIKey(row interface{ Scan(...interface{}) error }, key *models.APIKey) error {
+	return row.Scan(&key.ID, &key.Name, &key.Prefix, pq.Array(&key.Scopes), &key.CreatedBy,
+		&key.ExpiresAt, &key.LastUsedAt, &key.RevokedAt, &key.CreatedAt)
+}
+
+func CreateAPIKeyHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.ErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	claims, _ := auth.GetUserFromContext(r.Context())
+
+	var createReq models.CreateAPIKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&createReq); err != nil {
+		utils.ErrorResponse(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	createReq.Name = strings.TrimSpace(createReq.Name)
+	if createReq.Name == "" || len(createReq.Name) > 255 {
+		utils.ErrorResponse(w, "name is required and must not exceed 255 characters", http.StatusBadRequest)
+		return
+	}
+	if len(createReq.Scopes) == 0 {
+		utils.ErrorResponse(w, "at least one scope is required", http.StatusBadRequest)
+		return
+	}
+	for _, scope := range createReq.Scopes {
+		if !auth.Permission(scope).Valid() {
+			utils.ErrorResponse(w, fmt.Sprintf("unknown scope %q", scope), http.StatusBadRequest)
+			return
+		}
+		// A key can never grant more than its creator holds.
+		if !claims.Can(auth.Permission(scope)) {
+			utils.ErrorResponse(w, fmt.Sprintf("cannot grant scope %q", scope), http.StatusForbidden)
+			return
+		}
+	}
+	if createReq.ExpiresAt != nil && createReq.ExpiresAt.Before(time.Now()) {
+		utils.ErrorResponse(w, "expires_at must be in the future", http.StatusBadRequest)
+		return
+	}
+
+	key, prefix, keyHash, err := auth.GenerateAPIKey()
+	if err != nil {
+		utils.ErrorResponse(w, "Failed to create API key", http.StatusInternalServerError)
+		return
+	}
+
+	var apiKey models.APIKey
+	err = scanAPIKey(database.DB.QueryRow(`
+		INSERT INTO api_keys (name, prefix, key_hash, scopes, created_by, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING `+apiKeyColumns,
+		createReq.Name, prefix, keyHash, pq.Array(createReq.Scopes), actorID(claims), createReq.ExpiresAt), &apiKey)
+	if err != nil {
+		log.Printf("CreateAPIKeyHandler: error=%v", err)
+		utils.ErrorResponse(w, "Failed to create API key", http.StatusInternalServerError)
+		return
+	}
+
+	apiKey.Key = key
+	utils.SuccessResponse(w, apiKey, http.StatusCreated)
+}
+
+func GetAPIKeysHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.ErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys WHERE revoked_at IS NULL ORDER BY id`
+	if r.URL.Query().Get("include_revoked") == "true" {
+		query = `SELECT ` + apiKeyColumns + ` FROM api_keys ORDER BY id`
+	}
+
+	rows, err := database.DB.Query(query)
+	if err != nil {
+		log.Printf("GetAPIKeysHandler: query error: %v", err)
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	keys := []models.APIKey{}
+	for rows.Next() {
+		var key models.APIKey
+		if err := scanAPIKey(rows, &key); err != nil {
+			utils.ErrorResponse(w, "Error scanning results", http.StatusInternalServerError)
+			return
+		}
+		keys = append(keys, key)
+	}
+
+	if err = rows.Err(); err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	utils.SuccessResponse(w, models.APIKeysResponse{APIKeys: keys, Count: len(keys)}, http.StatusOK)
+}
+
+func RevokeAPIKeyHandler(w http.ResponseWriter, r *http.Request) {
+	keyID, err := parseIDParam(r, "id")
+	if err != nil {
+		utils.ErrorResponse(w, "Invalid API key ID", http.StatusBadRequest)
+		return
+	}
+
+	result, err := database.DB.Exec(`
+		UPDATE api_keys SET revoked_at = $2 WHERE id = $1 AND revoked_at IS NULL
+	`, keyID, time.Now())
+	if err != nil {
+		utils.ErrorResponse(w, "Failed to revoke API key", http.StatusInternalServerError)
+		return
+	}
+
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		utils.ErrorResponse(w, "API key not found", http.StatusNotFound)
+		return
+	}
+
+	utils.SuccessResponse(w, nil, http.StatusNoContent)
+}
@@ -0,0 +1,516 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Sea-Chels/go-practice-1/internal/database"
+	"github.com/Sea-Chels/go-practice-1/internal/models"
+	"github.com/Sea-Chels/go-practice-1/internal/utils"
+)
+
+const (
+	maxCategoryWeight   = 999.99
+	maxAssignmentPoints = 99999.99
+)
+
+const gradeCategoryColumns = `id, section_id, name, weight, created_at, updated_at`
+
+func scanGradeCategory(row interface{ Scan(...interface{}) error }, category *models.GradeCategory) error {
+	return row.Scan(&category.ID, &category.SectionID, &category.Name, &category.Weight,
+		&category.CreatedAt, &category.UpdatedAt)
+}
+
+// assignmentSelect reads assignments together with their category name.
+const assignmentSelect = `
+	SELECT a.id, a.section_id, a.category_id, gc.name, a.title, a.description, a.max_points, a.due_date,
+	       a.created_at, a.updated_at
+	FROM assignments a
+	JOIN grade_categories gc ON gc.id = a.category_id`
+
+func scanAssignment(row interface{ Scan(...interface{}) error }, assignment *models.Assignment) error {
+	return row.Scan(&assignment.ID, &assignment.SectionID, &assignment.CategoryID, &assignment.CategoryName,
+		&assignment.Title, &assignment.Description, &assignment.MaxPoints, &assignment.DueDate,
+		&assignment.CreatedAt, &assignment.UpdatedAt)
+}
+
+func getAssignment(db dbExecutor, assignmentID int) (models.Assignment, error) {
+	var assignment models.Assignment
+	err := scanAssignment(db.QueryRow(assignmentSelect+`
+		WHERE a.id = $1
+	`, assignmentID), &assignment)
+	return assignment, err
+}
+
+// sectionFromParam reads the section ID route variable, writing the error
+// response itself when the section does not exist.
+func sectionFromParam(w http.ResponseWriter, r *http.Request) (int, bool) {
+	sectionID, err := parseIDParam(r, "id")
+	if err != nil {
+		utils.ErrorResponse(w, "Invalid section ID", http.StatusBadRequest)
+		return 0, false
+	}
+
+	if exists, err := activeRowExists(database.DB, "sections", sectionID); err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return 0, false
+	} else if !exists {
+		utils.ErrorResponse(w, "Section not found", http.StatusNotFound)
+		return 0, false
+	}
+	return sectionID, true
+}
+
+func validateGradeCategoryRequest(req *models.CreateGradeCategoryRequest) error {
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if err := utils.ValidateMaxLength("name", req.Name, 50); err != nil {
+		return err
+	}
+	if req.Weight <= 0 || req.Weight > maxCategoryWeight {
+		return fmt.Errorf("weight must be greater than 0 and at most %.2f", maxCategoryWeight)
+	}
+	return nil
+}
+
+// GetGradeCategoriesHandler lists the grade categories of a section.
+func GetGradeCategoriesHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.ErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	sectionID, ok := sectionFromParam(w, r)
+	if !ok {
+		return
+	}
+
+	rows, err := database.DB.Query(`
+		SELECT `+gradeCategoryColumns+` FROM grade_categories WHERE section_id = $1 ORDER BY id
+	`, sectionID)
+	if err != nil {
+		log.Printf("GetGradeCategoriesHandler: query error: %v", err)
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	response := models.GradeCategoriesResponse{Categories: []models.GradeCategory{}}
+	for rows.Next() {
+		var category models.GradeCategory
+		if err := scanGradeCategory(rows, &category); err != nil {
+			utils.ErrorResponse(w, "Error scanning results", http.StatusInternalServerError)
+			return
+		}
+		response.Categories = append(response.Categories, category)
+		response.TotalWeight += category.Weight
+	}
+
+	if err = rows.Err(); err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	response.Count = len(response.Categories)
+	utils.SuccessResponse(w, response, http.StatusOK)
+}
+
+func CreateGradeCategoryHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.ErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	sectionID, ok := sectionFromParam(w, r)
+	if !ok {
+		return
+	}
+
+	var createReq models.CreateGradeCategoryRequest
+	if err := json.NewDecoder(r.Body).Decode(&createReq); err != nil {
+		utils.ErrorResponse(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := validateGradeCategoryRequest(&createReq); err != nil {
+		utils.ErrorResponse(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var category models.GradeCategory
+	err := scanGradeCategory(database.DB.QueryRow(`
+		INSERT INTO grade_categories (section_id, name, weight, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $4)
+		RETURNING `+gradeCategoryColumns,
+		sectionID, createReq.Name, createReq.Weight, time.Now()), &category)
+
+	if isUniqueViolation(err) {
+		utils.ErrorResponse(w, "The section already has a category with this name", http.StatusConflict)
+		return
+	} else if err != nil {
+		log.Printf("CreateGradeCategoryHandler: error=%v", err)
+		utils.ErrorResponse(w, "Failed to create grade category", http.StatusInternalServerError)
+		return
+	}
+
+	utils.SuccessResponse(w, category, http.StatusCreated)
+}
+
+func UpdateGradeCategoryHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		utils.ErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	categoryID, err := parseIDParam(r, "id")
+	if err != nil {
+		utils.ErrorResponse(w, "Invalid grade category ID", http.StatusBadRequest)
+		return
+	}
+
+	var updateReq models.CreateGradeCategoryRequest
+	if err := json.NewDecoder(r.Body).Decode(&updateReq); err != nil {
+		utils.ErrorResponse(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := validateGradeCategoryRequest(&updateReq); err != nil {
+		utils.ErrorResponse(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var category models.GradeCategory
+	err = scanGradeCategory(database.DB.QueryRow(`
+		UPDATE grade_categories
+		SET name = $2, weight = $3, updated_at = $4
+		WHERE id = $1
+		RETURNING `+gradeCategoryColumns,
+		categoryID, updateReq.Name, updateReq.Weight, time.Now()), &category)
+
+	if err == sql.ErrNoRows {
+		utils.ErrorResponse(w, "Grade category not found", http.StatusNotFound)
+		return
+	} else if isUniqueViolation(err) {
+		utils.ErrorResponse(w, "The section already has a category with this name", http.StatusConflict)
+		return
+	} else if err != nil {
+		log.Printf("UpdateGradeCategoryHandler: error=%v", err)
+		utils.ErrorResponse(w, "Failed to update grade category", http.StatusInternalServerError)
+		return
+	}
+
+	utils.SuccessResponse(w, category, http.StatusOK)
+}
+
+// DeleteGradeCategoryHandler deletes a category without assignments.
+func DeleteGradeCategoryHandler(w http.ResponseWriter, r *http.Request) {
+	categoryID, err := parseIDParam(r, "id")
+	if err != nil {
+		utils.ErrorResponse(w, "Invalid grade category ID", http.StatusBadRequest)
+		return
+	}
+
+	result, err := database.DB.Exec(`
+		DELETE FROM grade_categories
+		WHERE id = $1 AND NOT EXISTS (SELECT 1 FROM assignments WHERE category_id = $1)
+	`, categoryID)
+	if err != nil {
+		log.Printf("DeleteGradeCategoryHandler: error=%v", err)
+		utils.ErrorResponse(w, "Failed to delete grade category", http.StatusInternalServerError)
+		return
+	}
+
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		var exists bool
+		if err := database.DB.QueryRow(`SELECT EXISTS(SELECT 1 FROM grade_categories WHERE id = $1)`, categoryID).Scan(&exists); err == nil && exists {
+			utils.ErrorResponse(w, "Category still has assignments; move or delete them first", http.StatusConflict)
+			return
+		}
+		utils.ErrorResponse(w, "Grade category not found", http.StatusNotFound)
+		return
+	}
+
+	utils.SuccessResponse(w, nil, http.StatusNoContent)
+}
+
+func validateAssignmentRequest(req *models.CreateAssignmentRequest) (*time.Time, error) {
+	req.Title = strings.TrimSpace(req.Title)
+	req.Description = strings.TrimSpace(req.Description)
+
+	if req.CategoryID <= 0 {
+		return nil, fmt.Errorf("category_id is required")
+	}
+	if req.Title == "" {
+		return nil, fmt.Errorf("title is required")
+	}
+	if err := utils.ValidateMaxLength("title", req.Title, 255); err != nil {
+		return nil, err
+	}
+	if req.MaxPoints <= 0 || req.MaxPoints > maxAssignmentPoints {
+		return nil, fmt.Errorf("max_points must be greater than 0 and at most %.2f", maxAssignmentPoints)
+	}
+	return utils.ParseDate("due_date", req.DueDate)
+}
+
+// checkAssignmentCategory makes sure the category belongs to the section. It
+// returns a client error message, or "" when it does.
+func checkAssignmentCategory(db dbExecutor, sectionID, categoryID int) (string, error) {
+	var exists bool
+	err := db.QueryRow(`
+		SELECT EXISTS(SELECT 1 FROM grade_categories WHERE id = $1 AND section_id = $2)
+	`, categoryID, sectionID).Scan(&exists)
+	if err != nil || exists {
+		return "", err
+	}
+	return "category_id does not refer to a grade category of the section", nil
+}
+
+// GetSectionAssignmentsHandler lists the assignments of a section by due
+// date, optionally for one category_id.
+func GetSectionAssignmentsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.ErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	sectionID, ok := sectionFromParam(w, r)
+	if !ok {
+		return
+	}
+
+	conditions := []string{"a.section_id = $1"}
+	args := []interface{}{sectionID}
+	if value := r.URL.Query().Get("category_id"); value != "" {
+		categoryID, err := strconv.Atoi(value)
+		if err != nil || categoryID <= 0 {
+			utils.ErrorResponse(w, "category_id must be a positive integer", http.StatusBadRequest)
+			return
+		}
+		args = append(args, categoryID)
+		conditions = append(conditions, "a.category_id = $2")
+	}
+
+	rows, err := database.DB.Query(assignmentSelect+`
+		`+whereClause(conditions)+`
+		ORDER BY a.due_date NULLS LAST, a.id
+	`, args...)
+	if err != nil {
+		log.Printf("GetSectionAssignmentsHandler: query error: %v", err)
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	assignments := []models.Assignment{}
+	for rows.Next() {
+		var assignment models.Assignment
+		if err := scanAssignment(rows, &assignment); err != nil {
+			utils.ErrorResponse(w, "Error scanning results", http.StatusInternalServerError)
+			return
+		}
+		assignments = append(assignments, assignment)
+	}
+
+	if err = rows.Err(); err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	utils.SuccessResponse(w, models.AssignmentsResponse{Assignments: assignments, Count: len(assignments)}, http.StatusOK)
+}
+
+func GetAssignmentHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.ErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	assignmentID, err := parseIDParam(r, "id")
+	if err != nil {
+		utils.ErrorResponse(w, "Invalid assignment ID", http.StatusBadRequest)
+		return
+	}
+
+	assignment, err := getAssignment(database.DB, assignmentID)
+	if err == sql.ErrNoRows {
+		utils.ErrorResponse(w, "Assignment not found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	utils.SuccessResponse(w, assignment, http.StatusOK)
+}
+
+func CreateAssignmentHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.ErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	sectionID, ok := sectionFromParam(w, r)
+	if !ok {
+		return
+	}
+
+	var createReq models.CreateAssignmentRequest
+	if err := json.NewDecoder(r.Body).Decode(&createReq); err != nil {
+		utils.ErrorResponse(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	dueDate, err := validateAssignmentRequest(&createReq)
+	if err != nil {
+		utils.ErrorResponse(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if message, err := checkAssignmentCategory(database.DB, sectionID, createReq.CategoryID); err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	} else if message != "" {
+		utils.ErrorResponse(w, message, http.StatusBadRequest)
+		return
+	}
+
+	var assignmentID int
+	err = database.DB.QueryRow(`
+		INSERT INTO assignments (section_id, category_id, title, description, max_points, due_date, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $7)
+		RETURNING id
+	`, sectionID, createReq.CategoryID, createReq.Title, nullableString(createReq.Description),
+		createReq.MaxPoints, dueDate, time.Now()).Scan(&assignmentID)
+	if err != nil {
+		log.Printf("CreateAssignmentHandler: error=%v", err)
+		utils.ErrorResponse(w, "Failed to create assignment", http.StatusInternalServerError)
+		return
+	}
+
+	assignment, err := getAssignment(database.DB, assignmentID)
+	if err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	utils.SuccessResponse(w, assignment, http.StatusCreated)
+}
+
+// UpdateAssignmentHandler replaces an assignment within its section.
+// max_points cannot be lowered below a score already entered.
+func UpdateAssignmentHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		utils.ErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	assignmentID, err := parseIDParam(r, "id")
+	if err != nil {
+		utils.ErrorResponse(w, "Invalid assignment ID", http.StatusBadRequest)
+		return
+	}
+
+	var updateReq models.CreateAssignmentRequest
+	if err := json.NewDecoder(r.Body).Decode(&updateReq); err != nil {
+		utils.ErrorResponse(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	dueDate, err := validateAssignmentRequest(&updateReq)
+	if err != nil {
+		utils.ErrorResponse(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	tx, err := database.DB.Begin()
+	if err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	// Locking the assignment keeps scores from being entered meanwhile.
+	var sectionID int
+	var highest float64
+	err = tx.QueryRow(`
+		SELECT section_id, COALESCE((SELECT MAX(points) FROM scores WHERE assignment_id = a.id), 0)
+		FROM assignments a
+		WHERE a.id = $1
+		FOR UPDATE
+	`, assignmentID).Scan(&sectionID, &highest)
+	if err == sql.ErrNoRows {
+		utils.ErrorResponse(w, "Assignment not found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	if updateReq.MaxPoints < highest {
+		utils.ErrorResponse(w, fmt.Sprintf("max_points must be at least %g, the highest score entered", highest), http.StatusConflict)
+		return
+	}
+
+	if message, err := checkAssignmentCategory(tx, sectionID, updateReq.CategoryID); err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	} else if message != "" {
+		utils.ErrorResponse(w, message, http.StatusBadRequest)
+		return
+	}
+
+	_, err = tx.Exec(`
+		UPDATE assignments
+		SET category_id = $2, title = $3, description = $4, max_points = $5, due_date = $6, updated_at = $7
+		WHERE id = $1
+	`, assignmentID, updateReq.CategoryID, updateReq.Title, nullableString(updateReq.Description),
+		updateReq.MaxPoints, dueDate, time.Now())
+	if err != nil {
+		log.Printf("UpdateAssignmentHandler: error=%v", err)
+		utils.ErrorResponse(w, "Failed to update assignment", http.StatusInternalServerError)
+		return
+	}
+
+	assignment, err := getAssignment(tx, assignmentID)
+	if err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	utils.SuccessResponse(w, assignment, http.StatusOK)
+}
+
+// DeleteAssignmentHandler deletes an assignment together with its scores.
+func DeleteAssignmentHandler(w http.ResponseWriter, r *http.Request) {
+	assignmentID, err := parseIDParam(r, "id")
+	if err != nil {
+		utils.ErrorResponse(w, "Invalid assignment ID", http.StatusBadRequest)
+		return
+	}
+
+	result, err := database.DB.Exec(`DELETE FROM assignments WHERE id = $1`, assignmentID)
+	if err != nil {
+		log.Printf("DeleteAssignmentHandler: error=%v", err)
+		utils.ErrorResponse(w, "Failed to delete assignment", http.StatusInternalServerError)
+		return
+	}
+
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		utils.ErrorResponse(w, "Assignment not found", http.StatusNotFound)
+		return
+	}
+
+	utils.SuccessResponse(w, nil, http.StatusNoContent)
+}
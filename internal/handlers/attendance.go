@@ -0,0 +1,626 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Sea-Chels/go-practice-1/internal/audit"
+	"github.com/Sea-Chels/go-practice-1/internal/auth"
+	"github.com/Sea-Chels/go-practice-1/internal/calendar"
+	"github.com/Sea-Chels/go-practice-1/internal/database"
+	"github.com/Sea-Chels/go-practice-1/internal/models"
+	"github.com/Sea-Chels/go-practice-1/internal/utils"
+	"github.com/lib/pq"
+)
+
+// maxRosterRecords caps a roster submission; a whole grade fits comfortably.
+const maxRosterRecords = 1000
+
+const maxAttendanceNotesLength = 500
+
+const attendanceColumns = `id, student_id, section_id, date, status, notes, recorded_by, created_at, updated_at`
+
+// attendanceSelect reads records together with the student's name.
+var attendanceSelect = `
+	SELECT ` + qualifiedColumns("a", attendanceColumns) + `, st.name
+	FROM attendance_records a
+	JOIN students st ON st.id = a.student_id`
+
+func scanAttendanceRecord(row interface{ Scan(...interface{}) error }, record *models.AttendanceRecord, extra ...interface{}) error {
+	dest := []interface{}{&record.ID, &record.StudentID, &record.SectionID, &record.Date, &record.Status,
+		&record.Notes, &record.RecordedBy, &record.CreatedAt, &record.UpdatedAt}
+	return row.Scan(append(dest, extra...)...)
+}
+
+// getAttendanceRecord loads a record with its corrections, oldest first.
+func getAttendanceRecord(db dbExecutor, recordID int) (models.AttendanceRecord, error) {
+	var record models.AttendanceRecord
+	err := scanAttendanceRecord(db.QueryRow(attendanceSelect+`
+		WHERE a.id = $1
+	`, recordID), &record, &record.StudentName)
+	if err != nil {
+		return record, err
+	}
+
+	rows, err := db.Query(`
+		SELECT id, attendance_id, previous_status, new_status, reason, corrected_by, corrected_at
+		FROM attendance_corrections
+		WHERE attendance_id = $1
+		ORDER BY corrected_at, id
+	`, recordID)
+	if err != nil {
+		return record, err
+	}
+	defer rows.Close()
+
+	record.Corrections = []models.AttendanceCorrection{}
+	for rows.Next() {
+		var correction models.AttendanceCorrection
+		if err := rows.Scan(&correction.ID, &correction.AttendanceID, &correction.PreviousStatus,
+			&correction.NewStatus, &correction.Reason, &correction.CorrectedBy, &correction.CorrectedAt); err != nil {
+			return record, err
+		}
+		record.Corrections = append(record.Corrections, correction)
+	}
+	return record, rows.Err()
+}
+
+func validateRosterAttendanceRequest(req *models.RosterAttendanceRequest) (time.Time, error) {
+	date, err := utils.ParseDate("date", req.Date)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if date == nil {
+		return time.Time{}, fmt.Errorf("date is required")
+	}
+	if date.After(calendar.Today()) {
+		return time.Time{}, fmt.Errorf("date must not be in the future")
+	}
+	if req.SectionID != nil && *req.SectionID <= 0 {
+		return time.Time{}, fmt.Errorf("section_id must be a section ID")
+	}
+	if len(req.Records) == 0 || len(req.Records) > maxRosterRecords {
+		return time.Time{}, fmt.Errorf("records must contain between 1 and %d items", maxRosterRecords)
+	}
+
+	seen := map[int]bool{}
+	for i := range req.Records {
+		entry := &req.Records[i]
+		entry.Status = strings.TrimSpace(entry.Status)
+		entry.Notes = strings.TrimSpace(entry.Notes)
+
+		if entry.StudentID <= 0 {
+			return time.Time{}, fmt.Errorf("records[%d].student_id is required", i)
+		}
+		if seen[entry.StudentID] {
+			return time.Time{}, fmt.Errorf("records[%d].student_id is listed more than once", i)
+		}
+		seen[entry.StudentID] = true
+		if err := utils.ValidateAttendanceStatus(entry.Status); err != nil {
+			return time.Time{}, fmt.Errorf("records[%d].%v", i, err)
+		}
+		if err := utils.ValidateMaxLength("notes", entry.Notes, maxAttendanceNotesLength); err != nil {
+			return time.Time{}, fmt.Errorf("records[%d].%v", i, err)
+		}
+	}
+	return *date, nil
+}
+
+// rosterStudents returns the names of the listed students attendance may be
+// taken for: those on the section roster, or all active students for daily
+// attendance.
+func rosterStudents(tx *sql.Tx, sectionID *int, studentIDs []int) (map[int]string, error) {
+	var rows *sql.Rows
+	var err error
+	if sectionID != nil {
+		rows, err = tx.Query(`
+			SELECT st.id, st.name
+			FROM enrollments e
+			JOIN students st ON st.id = e.student_id
+			WHERE e.section_id = $1 AND e.status = 'enrolled' AND st.deleted_at IS NULL
+			  AND st.id = ANY($2::int[])
+		`, *sectionID, pq.Array(studentIDs))
+	} else {
+		rows, err = tx.Query(`
+			SELECT id, name FROM students
+			WHERE id = ANY($1::int[]) AND deleted_at IS NULL AND status = 'active'
+		`, pq.Array(studentIDs))
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	names := map[int]string{}
+	for rows.Next() {
+		var id int
+		var name string
+		if err := rows.Scan(&id, &name); err != nil {
+			return nil, err
+		}
+		names[id] = name
+	}
+	return names, rows.Err()
+}
+
+// insertAttendance records a status unless the student already has a record
+// for the day (and section), in which case that record is returned instead.
+// It reports whether a record was created.
+func insertAttendance(tx *sql.Tx, studentID int, sectionID *int, date time.Time, entry models.RosterAttendanceEntry,
+	recordedBy *int) (models.AttendanceRecord, bool, error) {
+	conflict := `(student_id, date) WHERE section_id IS NULL`
+	if sectionID != nil {
+		conflict = `(student_id, section_id, date) WHERE section_id IS NOT NULL`
+	}
+
+	var record models.AttendanceRecord
+	now := time.Now()
+	err := scanAttendanceRecord(tx.QueryRow(`
+		INSERT INTO attendance_records (student_id, section_id, date, status, notes, recorded_by, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $7)
+		ON CONFLICT `+conflict+` DO NOTHING
+		RETURNING `+attendanceColumns,
+		studentID, sectionID, date, entry.Status, nullableString(entry.Notes), recordedBy, now), &record)
+	if err == nil {
+		return record, true, nil
+	} else if err != sql.ErrNoRows {
+		return record, false, err
+	}
+
+	err = scanAttendanceRecord(tx.QueryRow(`
+		SELECT `+attendanceColumns+` FROM attendance_records
+		WHERE student_id = $1 AND section_id IS NOT DISTINCT FROM $2 AND date = $3
+	`, studentID, sectionID, date), &record)
+	return record, false, err
+}
+
+// RecordRosterAttendanceHandler takes attendance for a class in one request.
+// Students who already have a record keep it when the status is the same;
+// a different status must go through a correction. Nothing is saved unless
+// every record is accepted.
+func RecordRosterAttendanceHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.ErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	claims, _ := auth.GetUserFromContext(r.Context())
+
+	var rosterReq models.RosterAttendanceRequest
+	if err := json.NewDecoder(r.Body).Decode(&rosterReq); err != nil {
+		utils.ErrorResponse(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	date, err := validateRosterAttendanceRequest(&rosterReq)
+	if err != nil {
+		utils.ErrorResponse(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	tx, err := database.DB.Begin()
+	if err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	if instructional, err := calendar.IsInstructionalDay(tx, date); err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	} else if !instructional {
+		utils.ErrorResponse(w, "date is not an instructional day in the academic calendar", http.StatusBadRequest)
+		return
+	}
+
+	notListed := "student is not an active student"
+	if rosterReq.SectionID != nil {
+		if exists, err := activeRowExists(tx, "sections", *rosterReq.SectionID); err != nil {
+			utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+			return
+		} else if !exists {
+			utils.ErrorResponse(w, "section_id does not refer to an existing section", http.StatusBadRequest)
+			return
+		}
+		notListed = "student is not enrolled in the section"
+	}
+
+	studentIDs := make([]int, len(rosterReq.Records))
+	for i, entry := range rosterReq.Records {
+		studentIDs[i] = entry.StudentID
+	}
+	names, err := rosterStudents(tx, rosterReq.SectionID, studentIDs)
+	if err != nil {
+		log.Printf("RecordRosterAttendanceHandler: error=%v", err)
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	response := models.RosterAttendanceResponse{
+		Date:      date.Format("2006-01-02"),
+		SectionID: rosterReq.SectionID,
+		Results:   []models.RosterAttendanceResult{},
+	}
+	for i, entry := range rosterReq.Records {
+		result := models.RosterAttendanceResult{Index: i, StudentID: entry.StudentID}
+
+		name, ok := names[entry.StudentID]
+		if !ok {
+			result.Error = notListed
+			response.Failed++
+			response.Results = append(response.Results, result)
+			continue
+		}
+
+		record, created, err := insertAttendance(tx, entry.StudentID, rosterReq.SectionID, date, entry, actorID(claims))
+		if err != nil {
+			log.Printf("RecordRosterAttendanceHandler: record %d error=%v", i, err)
+			utils.ErrorResponse(w, "Failed to record attendance", http.StatusInternalServerError)
+			return
+		}
+		record.StudentName = name
+
+		switch {
+		case created:
+			response.Created++
+		case record.Status == entry.Status:
+			response.Unchanged++
+		default:
+			result.Error = fmt.Sprintf("attendance is already recorded as %s; submit a correction to change it", record.Status)
+			response.Failed++
+		}
+		result.Created = created
+		result.Record = &record
+		response.Results = append(response.Results, result)
+	}
+
+	if response.Failed > 0 {
+		utils.SuccessResponse(w, response, http.StatusUnprocessableEntity)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	response.Committed = true
+	utils.SuccessResponse(w, response, http.StatusOK)
+}
+
+// GetAttendanceHandler lists attendance records filtered by student_id,
+// section_id, date or the from/to range, and status. At least one of
+// student_id, section_id and date is required.
+func GetAttendanceHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.ErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+	if query.Get("student_id") == "" && query.Get("section_id") == "" && query.Get("date") == "" {
+		utils.ErrorResponse(w, "At least one of student_id, section_id and date is required", http.StatusBadRequest)
+		return
+	}
+
+	var conditions []string
+	var args []interface{}
+	for _, param := range []string{"student_id", "section_id"} {
+		value := query.Get(param)
+		if value == "" {
+			continue
+		}
+		id, err := strconv.Atoi(value)
+		if err != nil || id <= 0 {
+			utils.ErrorResponse(w, fmt.Sprintf("%s must be a positive integer", param), http.StatusBadRequest)
+			return
+		}
+		args = append(args, id)
+		conditions = append(conditions, fmt.Sprintf("a.%s = $%d", param, len(args)))
+	}
+	for _, filter := range []struct{ param, condition string }{
+		{"date", "a.date = $%d"},
+		{"from", "a.date >= $%d"},
+		{"to", "a.date <= $%d"},
+	} {
+		date, err := utils.ParseDate(filter.param, query.Get(filter.param))
+		if err != nil {
+			utils.ErrorResponse(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if date != nil {
+			args = append(args, *date)
+			conditions = append(conditions, fmt.Sprintf(filter.condition, len(args)))
+		}
+	}
+	if status := query.Get("status"); status != "" {
+		if err := utils.ValidateAttendanceStatus(status); err != nil {
+			utils.ErrorResponse(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		args = append(args, status)
+		conditions = append(conditions, fmt.Sprintf("a.status = $%d", len(args)))
+	}
+
+	rows, err := database.DB.Query(attendanceSelect+`
+		`+whereClause(conditions)+`
+		ORDER BY a.date, a.section_id NULLS FIRST, st.last_name, st.first_name, a.id
+	`, args...)
+	if err != nil {
+		log.Printf("GetAttendanceHandler: query error: %v", err)
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	records := []models.AttendanceRecord{}
+	for rows.Next() {
+		var record models.AttendanceRecord
+		if err := scanAttendanceRecord(rows, &record, &record.StudentName); err != nil {
+			utils.ErrorResponse(w, "Error scanning results", http.StatusInternalServerError)
+			return
+		}
+		records = append(records, record)
+	}
+
+	if err = rows.Err(); err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	utils.SuccessResponse(w, models.AttendanceResponse{Records: records, Count: len(records)}, http.StatusOK)
+}
+
+// GetAttendanceRecordHandler returns a record with its corrections.
+func GetAttendanceRecordHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.ErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	recordID, err := parseIDParam(r, "id")
+	if err != nil {
+		utils.ErrorResponse(w, "Invalid attendance record ID", http.StatusBadRequest)
+		return
+	}
+
+	record, err := getAttendanceRecord(database.DB, recordID)
+	if err == sql.ErrNoRows {
+		utils.ErrorResponse(w, "Attendance record not found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	utils.SuccessResponse(w, record, http.StatusOK)
+}
+
+// CorrectAttendanceHandler changes a recorded status, keeping the previous
+// one and the reason as a correction.
+func CorrectAttendanceHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.ErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	claims, _ := auth.GetUserFromContext(r.Context())
+
+	recordID, err := parseIDParam(r, "id")
+	if err != nil {
+		utils.ErrorResponse(w, "Invalid attendance record ID", http.StatusBadRequest)
+		return
+	}
+
+	var correctReq models.CorrectAttendanceRequest
+	if err := json.NewDecoder(r.Body).Decode(&correctReq); err != nil {
+		utils.ErrorResponse(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	correctReq.Status = strings.TrimSpace(correctReq.Status)
+	correctReq.Reason = strings.TrimSpace(correctReq.Reason)
+	if err := utils.ValidateAttendanceStatus(correctReq.Status); err != nil {
+		utils.ErrorResponse(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if correctReq.Reason == "" {
+		utils.ErrorResponse(w, "reason is required", http.StatusBadRequest)
+		return
+	}
+	if err := utils.ValidateMaxLength("reason", correctReq.Reason, maxAttendanceNotesLength); err != nil {
+		utils.ErrorResponse(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if correctReq.Notes != nil {
+		notes := strings.TrimSpace(*correctReq.Notes)
+		if err := utils.ValidateMaxLength("notes", notes, maxAttendanceNotesLength); err != nil {
+			utils.ErrorResponse(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		correctReq.Notes = &notes
+	}
+
+	tx, err := database.DB.Begin()
+	if err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	var current models.AttendanceRecord
+	err = scanAttendanceRecord(tx.QueryRow(`
+		SELECT `+attendanceColumns+` FROM attendance_records WHERE id = $1 FOR UPDATE
+	`, recordID), &current)
+	if err == sql.ErrNoRows {
+		utils.ErrorResponse(w, "Attendance record not found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	notes := stringValue(current.Notes)
+	if correctReq.Notes != nil {
+		notes = *correctReq.Notes
+	}
+	if correctReq.Status == current.Status && notes == stringValue(current.Notes) {
+		utils.ErrorResponse(w, "The correction does not change the record", http.StatusBadRequest)
+		return
+	}
+
+	now := time.Now()
+	if _, err := tx.Exec(`
+		UPDATE attendance_records SET status = $2, notes = $3, updated_at = $4 WHERE id = $1
+	`, recordID, correctReq.Status, nullableString(notes), now); err != nil {
+		log.Printf("CorrectAttendanceHandler: error=%v", err)
+		utils.ErrorResponse(w, "Failed to correct attendance", http.StatusInternalServerError)
+		return
+	}
+
+	if _, err := tx.Exec(`
+		INSERT INTO attendance_corrections (attendance_id, previous_status, new_status, reason, corrected_by, corrected_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`, recordID, current.Status, correctReq.Status, correctReq.Reason, actorID(claims), now); err != nil {
+		log.Printf("CorrectAttendanceHandler: error=%v", err)
+		utils.ErrorResponse(w, "Failed to correct attendance", http.StatusInternalServerError)
+		return
+	}
+
+	if err := audit.Record(tx, audit.Entry{
+		ActorID:    actorID(claims),
+		Action:     audit.ActionAttendanceCorrected,
+		EntityType: "attendance",
+		EntityID:   strconv.Itoa(recordID),
+		Details: map[string]interface{}{
+			"student_id":      current.StudentID,
+			"section_id":      current.SectionID,
+			"date":            current.Date.Format("2006-01-02"),
+			"previous_status": current.Status,
+			"new_status":      correctReq.Status,
+			"reason":          correctReq.Reason,
+		},
+		IPAddress: utils.ClientIP(r),
+	}); err != nil {
+		log.Printf("CorrectAttendanceHandler: error=%v", err)
+		utils.ErrorResponse(w, "Failed to correct attendance", http.StatusInternalServerError)
+		return
+	}
+
+	record, err := getAttendanceRecord(tx, recordID)
+	if err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	utils.SuccessResponse(w, record, http.StatusOK)
+}
+
+// GetStudentAttendanceSummaryHandler counts a student's daily attendance, or
+// that of one section, between from and to. Without a range it covers the
+// current term up to today.
+func GetStudentAttendanceSummaryHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.ErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	studentID, err := parseIDParam(r, "id")
+	if err != nil {
+		utils.ErrorResponse(w, "Invalid student ID", http.StatusBadRequest)
+		return
+	}
+
+	query := r.URL.Query()
+	summary := models.AttendanceSummary{StudentID: studentID}
+	if value := query.Get("section_id"); value != "" {
+		sectionID, err := strconv.Atoi(value)
+		if err != nil || sectionID <= 0 {
+			utils.ErrorResponse(w, "section_id must be a positive integer", http.StatusBadRequest)
+			return
+		}
+		summary.SectionID = &sectionID
+	}
+
+	from, err := utils.ParseDate("from", query.Get("from"))
+	if err != nil {
+		utils.ErrorResponse(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	to, err := utils.ParseDate("to", query.Get("to"))
+	if err != nil {
+		utils.ErrorResponse(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	switch {
+	case from != nil && to != nil:
+		summary.From, summary.To = *from, *to
+	case from == nil && to == nil:
+		term, err := calendar.CurrentTerm(database.DB, "")
+		if errors.Is(err, calendar.ErrNoTerm) {
+			utils.ErrorResponse(w, "from and to are required when no term is in session", http.StatusBadRequest)
+			return
+		} else if err != nil {
+			utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+			return
+		}
+		summary.From, summary.To = term.StartDate, calendar.Today()
+	default:
+		utils.ErrorResponse(w, "from and to must be given together", http.StatusBadRequest)
+		return
+	}
+	if summary.To.Before(summary.From) {
+		utils.ErrorResponse(w, "to must not be before from", http.StatusBadRequest)
+		return
+	}
+
+	if exists, err := activeRowExists(database.DB, "students", studentID); err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	} else if !exists {
+		utils.ErrorResponse(w, "Student not found", http.StatusNotFound)
+		return
+	}
+
+	err = database.DB.QueryRow(`
+		SELECT COUNT(*),
+		       COUNT(*) FILTER (WHERE status = 'present'),
+		       COUNT(*) FILTER (WHERE status = 'absent'),
+		       COUNT(*) FILTER (WHERE status = 'tardy'),
+		       COUNT(*) FILTER (WHERE status = 'excused')
+		FROM attendance_records
+		WHERE student_id = $1 AND section_id IS NOT DISTINCT FROM $2 AND date BETWEEN $3 AND $4
+	`, studentID, summary.SectionID, summary.From, summary.To).Scan(&summary.Recorded, &summary.Present,
+		&summary.Absent, &summary.Tardy, &summary.Excused)
+	if err != nil {
+		log.Printf("GetStudentAttendanceSummaryHandler: error=%v", err)
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	if summary.InstructionalDays, err = calendar.InstructionalDays(database.DB, summary.From, summary.To); err != nil {
+		log.Printf("GetStudentAttendanceSummaryHandler: error=%v", err)
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	if counted := summary.Recorded - summary.Excused; counted > 0 {
+		rate := math.Round(float64(summary.Present+summary.Tardy)/float64(counted)*10000) / 10000
+		summary.AttendanceRate = &rate
+	}
+
+	utils.SuccessResponse(w, summary, http.StatusOK)
+}
@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Sea-Chels/go-practice-1/internal/database"
+	"github.com/Sea-Chels/go-practice-1/internal/models"
+	"github.com/Sea-Chels/go-practice-1/internal/utils"
+)
+
+const maxAuditLogLimit = 500
+
+// GetAuditLogsHandler returns the most recent audit entries, optionally
+// filtered by action, entity_type and entity_id.
+func GetAuditLogsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.ErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+	limit := 100
+	if value := query.Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > maxAuditLogLimit {
+			utils.ErrorResponse(w, fmt.Sprintf("limit must be between 1 and %d", maxAuditLogLimit), http.StatusBadRequest)
+			return
+		}
+		limit = parsed
+	}
+
+	var conditions []string
+	var args []interface{}
+	for _, column := range []string{"action", "entity_type", "entity_id"} {
+		if value := query.Get(column); value != "" {
+			args = append(args, value)
+			conditions = append(conditions, fmt.Sprintf("%s = $%d", column, len(args)))
+		}
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ")
+	}
+	args = append(args, limit)
+
+	rows, err := database.DB.Query(fmt.Sprintf(`
+		SELECT id, actor_user_id, action, entity_type, entity_id, details, ip_address, created_at
+		FROM audit_logs
+		%s
+		ORDER BY id DESC
+		LIMIT $%d
+	`, where, len(args)), args...)
+	if err != nil {
+		log.Printf("GetAuditLogsHandler: query error: %v", err)
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	entries := []models.AuditLog{}
+	for rows.Next() {
+		var entry models.AuditLog
+		var details []byte
+		if err := rows.Scan(&entry.ID, &entry.ActorUserID, &entry.Action, &entry.EntityType,
+			&entry.EntityID, &details, &entry.IPAddress, &entry.CreatedAt); err != nil {
+			utils.ErrorResponse(w, "Error scanning results", http.StatusInternalServerError)
+			return
+		}
+		entry.Details = details
+		entries = append(entries, entry)
+	}
+
+	if err = rows.Err(); err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	utils.SuccessResponse(w, models.AuditLogsResponse{AuditLogs: entries, Count: len(entries)}, http.StatusOK)
+}
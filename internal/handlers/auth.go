@@ -3,7 +3,11 @@ package handlers
 import (
 	"database/sql"
 	"encoding/json"
+	"fmt"
+	"log"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/Sea-Chels/go-practice-1/internal/auth"
 	"github.com/Sea-Chels/go-practice-1/internal/database"
@@ -30,39 +34,261 @@ func LoginHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Reject the attempt outright while the account is locked or the client
+	// is still inside its back-off period.
+	throttle := loadLoginThrottleConfig()
+	throttleKey := strings.ToLower(loginReq.Email)
+	clientIP := utils.ClientIP(r)
+	tx, err := beginLoginAttempt(throttle, throttleKey, clientIP)
+	if err != nil {
+		if blocked, ok := err.(*loginBlockedError); ok {
+			writeLoginBlocked(w, blocked)
+			return
+		}
+		log.Printf("LoginHandler: throttle check failed: %v", err)
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
 	// Get user from database
 	var user models.User
-	err := database.DB.QueryRow(`
-		SELECT id, email, password_hash 
+	err = tx.QueryRow(`
+		SELECT id, email, password_hash, role, totp_enabled_at IS NOT NULL, disabled_at
 		FROM users 
 		WHERE email = $1 AND deleted_at IS NULL
-	`, loginReq.Email).Scan(&user.ID, &user.Email, &user.PasswordHash)
+	`, loginReq.Email).Scan(&user.ID, &user.Email, &user.PasswordHash, &user.Role, &user.MFAEnabled, &user.DisabledAt)
 
-	if err == sql.ErrNoRows {
+	if err != nil && err != sql.ErrNoRows {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	// Compare password
+	if err == sql.ErrNoRows || bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(loginReq.Password)) != nil {
+		if err := recordLoginFailure(tx, throttle, throttleKey, clientIP); err != nil {
+			log.Printf("LoginHandler: failed to record login failure: %v", err)
+		} else if err := tx.Commit(); err != nil {
+			log.Printf("LoginHandler: failed to record login failure: %v", err)
+		}
 		utils.ErrorResponse(w, "Invalid credentials", http.StatusUnauthorized)
 		return
+	}
+
+	if user.DisabledAt != nil {
+		utils.ErrorResponse(w, "Account is disabled", http.StatusForbidden)
+		return
+	}
+
+	// With two-factor authentication enabled the password only earns a
+	// challenge token that must be exchanged at /auth/mfa/verify.
+	if user.MFAEnabled {
+		mfaToken, expiresAt, err := auth.GenerateMFAToken(user.ID, user.Email)
+		if err != nil {
+			utils.ErrorResponse(w, "Failed to generate token", http.StatusInternalServerError)
+			return
+		}
+
+		response := models.MFAChallengeResponse{
+			MFARequired: true,
+			MFAToken:    mfaToken,
+			ExpiresAt:   expiresAt,
+		}
+		utils.SuccessResponse(w, response, http.StatusOK)
+		return
+	}
+
+	familyID, err := auth.NewTokenFamily()
+	if err != nil {
+		utils.ErrorResponse(w, "Failed to generate token", http.StatusInternalServerError)
+		return
+	}
+
+	response, err := issueTokens(tx, user, familyID)
+	if err != nil {
+		log.Printf("LoginHandler: error=%v", err)
+		utils.ErrorResponse(w, "Failed to generate token", http.StatusInternalServerError)
+		return
+	}
+
+	if err := recordLoginSuccess(tx, throttleKey, clientIP); err != nil {
+		log.Printf("LoginHandler: error=%v", err)
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	utils.SuccessResponse(w, response, http.StatusOK)
+}
+
+// issueTokens signs a new access token and stores a new refresh token in the
+// given family.
+func issueTokens(db dbExecutor, user models.User, familyID string) (*models.LoginResponse, error) {
+	token, expiresAt, err := auth.GenerateToken(user.ID, user.Email, user.Role)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken, refreshHash, err := auth.GenerateOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+
+	refreshExpiresAt := time.Now().Add(auth.RefreshTokenTTL())
+	_, err = db.Exec(`
+		INSERT INTO refresh_tokens (user_id, family_id, token_hash, expires_at)
+		VALUES ($1, $2, $3, $4)
+	`, user.ID, familyID, refreshHash, refreshExpiresAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to store refresh token: %w", err)
+	}
+
+	return &models.LoginResponse{
+		Token:            token,
+		ExpiresAt:        expiresAt,
+		RefreshToken:     refreshToken,
+		RefreshExpiresAt: refreshExpiresAt,
+	}, nil
+}
+
+// revokeTokenFamily revokes every refresh token issued in the family.
+func revokeTokenFamily(db dbExecutor, familyID string) error {
+	_, err := db.Exec(`
+		UPDATE refresh_tokens
+		SET revoked_at = $2
+		WHERE family_id = $1 AND revoked_at IS NULL
+	`, familyID, time.Now())
+	return err
+}
+
+func RefreshHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.ErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var refreshReq models.RefreshTokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&refreshReq); err != nil || refreshReq.RefreshToken == "" {
+		utils.ErrorResponse(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	tx, err := database.DB.Begin()
+	if err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	var (
+		tokenID   int
+		familyID  string
+		expiresAt time.Time
+		usedAt    sql.NullTime
+		revokedAt sql.NullTime
+		user      models.User
+	)
+	err = tx.QueryRow(`
+		SELECT id, user_id, family_id, expires_at, used_at, revoked_at
+		FROM refresh_tokens
+		WHERE token_hash = $1
+		FOR UPDATE
+	`, auth.HashToken(refreshReq.RefreshToken)).Scan(&tokenID, &user.ID, &familyID, &expiresAt, &usedAt, &revokedAt)
+
+	if err == sql.ErrNoRows {
+		utils.ErrorResponse(w, "Invalid refresh token", http.StatusUnauthorized)
+		return
 	} else if err != nil {
 		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
 		return
 	}
 
-	// Compare password
-	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(loginReq.Password)); err != nil {
-		utils.ErrorResponse(w, "Invalid credentials", http.StatusUnauthorized)
+	// A token that was already rotated is being replayed: assume it was
+	// stolen and kill the whole family, including the legitimate successor.
+	if usedAt.Valid || revokedAt.Valid {
+		if err := revokeTokenFamily(tx, familyID); err != nil || tx.Commit() != nil {
+			utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+			return
+		}
+		log.Printf("RefreshHandler: refresh token reuse detected for user %d, family %s revoked", user.ID, familyID)
+		utils.ErrorResponse(w, "Invalid refresh token", http.StatusUnauthorized)
+		return
+	}
+
+	if time.Now().After(expiresAt) {
+		utils.ErrorResponse(w, "Refresh token expired", http.StatusUnauthorized)
+		return
+	}
+
+	err = tx.QueryRow(`
+		SELECT email, role
+		FROM users
+		WHERE id = $1 AND deleted_at IS NULL AND disabled_at IS NULL
+	`, user.ID).Scan(&user.Email, &user.Role)
+
+	if err == sql.ErrNoRows {
+		utils.ErrorResponse(w, "Invalid refresh token", http.StatusUnauthorized)
+		return
+	} else if err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
 		return
 	}
 
-	// Generate JWT token
-	token, expiresAt, err := auth.GenerateToken(user.ID, user.Email)
+	if _, err := tx.Exec(`UPDATE refresh_tokens SET used_at = $2 WHERE id = $1`, tokenID, time.Now()); err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	response, err := issueTokens(tx, user, familyID)
 	if err != nil {
+		log.Printf("RefreshHandler: error=%v", err)
 		utils.ErrorResponse(w, "Failed to generate token", http.StatusInternalServerError)
 		return
 	}
 
-	response := models.LoginResponse{
-		Token:     token,
-		ExpiresAt: expiresAt,
+	if err := tx.Commit(); err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
 	}
 
 	utils.SuccessResponse(w, response, http.StatusOK)
+}
+
+func LogoutHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.ErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var logoutReq models.RefreshTokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&logoutReq); err != nil || logoutReq.RefreshToken == "" {
+		utils.ErrorResponse(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	var familyID string
+	err := database.DB.QueryRow(`
+		SELECT family_id FROM refresh_tokens WHERE token_hash = $1
+	`, auth.HashToken(logoutReq.RefreshToken)).Scan(&familyID)
+
+	// Logging out with an unknown token is not an error; there is simply
+	// nothing left to revoke.
+	if err == sql.ErrNoRows {
+		utils.SuccessResponse(w, nil, http.StatusNoContent)
+		return
+	} else if err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	if err := revokeTokenFamily(database.DB, familyID); err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	utils.SuccessResponse(w, nil, http.StatusNoContent)
 }
\ No newline at end of file
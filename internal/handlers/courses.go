@@ -0,0 +1,234 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Sea-Chels/go-practice-1/internal/database"
+	"github.com/Sea-Chels/go-practice-1/internal/models"
+	"github.com/Sea-Chels/go-practice-1/internal/utils"
+)
+
+const courseColumns = `id, code, name, description, credits, created_at, updated_at, deleted_at`
+
+func scanCourse(row interface{ Scan(...interface{}) error }, course *models.Course) error {
+	return row.Scan(&course.ID, &course.Code, &course.Name, &course.Description, &course.Credits,
+		&course.CreatedAt, &course.UpdatedAt, &course.DeletedAt)
+}
+
+func validateCourseRequest(req *models.CreateCourseRequest) error {
+	req.Code = strings.ToUpper(strings.TrimSpace(req.Code))
+	req.Name = strings.TrimSpace(req.Name)
+	req.Description = strings.TrimSpace(req.Description)
+
+	if err := utils.ValidateCourseCode(req.Code); err != nil {
+		return err
+	}
+	if req.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if err := utils.ValidateMaxLength("name", req.Name, 255); err != nil {
+		return err
+	}
+	if req.Credits < 0 || req.Credits >= 100 {
+		return fmt.Errorf("credits must be between 0 and 99.99")
+	}
+	return nil
+}
+
+func GetCoursesHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.ErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := `SELECT ` + courseColumns + ` FROM courses WHERE deleted_at IS NULL ORDER BY code`
+	if r.URL.Query().Get("include_deleted") == "true" {
+		query = `SELECT ` + courseColumns + ` FROM courses ORDER BY code, id`
+	}
+
+	rows, err := database.DB.Query(query)
+	if err != nil {
+		log.Printf("GetCoursesHandler: query error: %v", err)
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	courses := []models.Course{}
+	for rows.Next() {
+		var course models.Course
+		if err := scanCourse(rows, &course); err != nil {
+			utils.ErrorResponse(w, "Error scanning results", http.StatusInternalServerError)
+			return
+		}
+		courses = append(courses, course)
+	}
+
+	if err = rows.Err(); err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	utils.SuccessResponse(w, models.CoursesResponse{Courses: courses, Count: len(courses)}, http.StatusOK)
+}
+
+func GetCourseHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.ErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	courseID, err := parseIDParam(r, "id")
+	if err != nil {
+		utils.ErrorResponse(w, "Invalid course ID", http.StatusBadRequest)
+		return
+	}
+
+	var course models.Course
+	err = scanCourse(database.DB.QueryRow(`
+		SELECT `+courseColumns+`
+		FROM courses
+		WHERE id = $1 AND deleted_at IS NULL
+	`, courseID), &course)
+
+	if err == sql.ErrNoRows {
+		utils.ErrorResponse(w, "Course not found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	utils.SuccessResponse(w, course, http.StatusOK)
+}
+
+func CreateCourseHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.ErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var createReq models.CreateCourseRequest
+	if err := json.NewDecoder(r.Body).Decode(&createReq); err != nil {
+		utils.ErrorResponse(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := validateCourseRequest(&createReq); err != nil {
+		utils.ErrorResponse(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var course models.Course
+	now := time.Now()
+	err := scanCourse(database.DB.QueryRow(`
+		INSERT INTO courses (code, name, description, credits, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $5)
+		RETURNING `+courseColumns,
+		createReq.Code, createReq.Name, nullableString(createReq.Description), createReq.Credits, now), &course)
+
+	if isUniqueViolation(err) {
+		utils.ErrorResponse(w, "A course with this code already exists", http.StatusConflict)
+		return
+	} else if err != nil {
+		log.Printf("CreateCourseHandler: error=%v", err)
+		utils.ErrorResponse(w, "Failed to create course", http.StatusInternalServerError)
+		return
+	}
+
+	utils.SuccessResponse(w, course, http.StatusCreated)
+}
+
+func UpdateCourseHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		utils.ErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	courseID, err := parseIDParam(r, "id")
+	if err != nil {
+		utils.ErrorResponse(w, "Invalid course ID", http.StatusBadRequest)
+		return
+	}
+
+	var updateReq models.CreateCourseRequest
+	if err := json.NewDecoder(r.Body).Decode(&updateReq); err != nil {
+		utils.ErrorResponse(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := validateCourseRequest(&updateReq); err != nil {
+		utils.ErrorResponse(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var course models.Course
+	err = scanCourse(database.DB.QueryRow(`
+		UPDATE courses
+		SET code = $2, name = $3, description = $4, credits = $5, updated_at = $6
+		WHERE id = $1 AND deleted_at IS NULL
+		RETURNING `+courseColumns,
+		courseID, updateReq.Code, updateReq.Name, nullableString(updateReq.Description), updateReq.Credits,
+		time.Now()), &course)
+
+	if err == sql.ErrNoRows {
+		utils.ErrorResponse(w, "Course not found", http.StatusNotFound)
+		return
+	} else if isUniqueViolation(err) {
+		utils.ErrorResponse(w, "A course with this code already exists", http.StatusConflict)
+		return
+	} else if err != nil {
+		log.Printf("UpdateCourseHandler: error=%v", err)
+		utils.ErrorResponse(w, "Failed to update course", http.StatusInternalServerError)
+		return
+	}
+
+	utils.SuccessResponse(w, course, http.StatusOK)
+}
+
+// DeleteCourseHandler soft deletes a course. Courses that still have
+// sections must have those deleted first.
+func DeleteCourseHandler(w http.ResponseWriter, r *http.Request) {
+	courseID, err := parseIDParam(r, "id")
+	if err != nil {
+		utils.ErrorResponse(w, "Invalid course ID", http.StatusBadRequest)
+		return
+	}
+
+	var hasSections bool
+	if err := database.DB.QueryRow(`
+		SELECT EXISTS(SELECT 1 FROM sections WHERE course_id = $1 AND deleted_at IS NULL)
+	`, courseID).Scan(&hasSections); err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	if hasSections {
+		utils.ErrorResponse(w, "Course still has sections; delete them first", http.StatusConflict)
+		return
+	}
+
+	now := time.Now()
+	result, err := database.DB.Exec(`
+		UPDATE courses
+		SET deleted_at = $2, updated_at = $3
+		WHERE id = $1 AND deleted_at IS NULL
+	`, courseID, now, now)
+	if err != nil {
+		log.Printf("DeleteCourseHandler: error=%v", err)
+		utils.ErrorResponse(w, "Failed to delete course", http.StatusInternalServerError)
+		return
+	}
+
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		utils.ErrorResponse(w, "Course not found", http.StatusNotFound)
+		return
+	}
+
+	utils.SuccessResponse(w, nil, http.StatusNoContent)
+}
@@ -0,0 +1,322 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Sea-Chels/go-practice-1/internal/calendar"
+	"github.com/Sea-Chels/go-practice-1/internal/database"
+	"github.com/Sea-Chels/go-practice-1/internal/models"
+	"github.com/Sea-Chels/go-practice-1/internal/utils"
+)
+
+const enrollmentColumns = `id, section_id, student_id, status, enrolled_at, dropped_at`
+
+func scanEnrollment(row interface{ Scan(...interface{}) error }, enrollment *models.Enrollment) error {
+	return row.Scan(&enrollment.ID, &enrollment.SectionID, &enrollment.StudentID, &enrollment.Status,
+		&enrollment.EnrolledAt, &enrollment.DroppedAt)
+}
+
+// EnrollStudentHandler enrolls a student into a section. The section and the
+// student are locked for the transaction, so concurrent requests cannot
+// overfill the section or enroll the student twice.
+func EnrollStudentHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.ErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	sectionID, err := parseIDParam(r, "id")
+	if err != nil {
+		utils.ErrorResponse(w, "Invalid section ID", http.StatusBadRequest)
+		return
+	}
+
+	var enrollReq models.EnrollStudentRequest
+	if err := json.NewDecoder(r.Body).Decode(&enrollReq); err != nil {
+		utils.ErrorResponse(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if enrollReq.StudentID <= 0 {
+		utils.ErrorResponse(w, "student_id is required", http.StatusBadRequest)
+		return
+	}
+
+	tx, err := database.DB.Begin()
+	if err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	var courseID, capacity int
+	var term string
+	err = tx.QueryRow(`
+		SELECT course_id, term, capacity FROM sections WHERE id = $1 AND deleted_at IS NULL FOR UPDATE
+	`, sectionID).Scan(&courseID, &term, &capacity)
+	if err == sql.ErrNoRows {
+		utils.ErrorResponse(w, "Section not found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	var status string
+	err = tx.QueryRow(`
+		SELECT status FROM students WHERE id = $1 AND deleted_at IS NULL FOR UPDATE
+	`, enrollReq.StudentID).Scan(&status)
+	if err == sql.ErrNoRows {
+		utils.ErrorResponse(w, "Student not found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	if status != models.StudentStatusActive {
+		utils.ErrorResponse(w, "Only active students can be enrolled; this student is "+status, http.StatusConflict)
+		return
+	}
+
+	// A student takes a course once per term, in whichever section.
+	var enrolledSection sql.NullInt64
+	var enrolled int
+	err = tx.QueryRow(`
+		SELECT (SELECT e.section_id
+		        FROM enrollments e JOIN sections s ON s.id = e.section_id
+		        WHERE e.student_id = $1 AND e.status = 'enrolled'
+		          AND s.course_id = $2 AND s.term = $3 AND s.deleted_at IS NULL
+		        LIMIT 1),
+		       (SELECT COUNT(*) FROM enrollments e JOIN students st ON st.id = e.student_id
+		        WHERE e.section_id = $4 AND e.status = 'enrolled' AND st.deleted_at IS NULL)
+	`, enrollReq.StudentID, courseID, term, sectionID).Scan(&enrolledSection, &enrolled)
+	if err != nil {
+		log.Printf("EnrollStudentHandler: error=%v", err)
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	if enrolledSection.Valid && int(enrolledSection.Int64) == sectionID {
+		utils.ErrorResponse(w, "Student is already enrolled in this section", http.StatusConflict)
+		return
+	} else if enrolledSection.Valid {
+		utils.ErrorResponse(w, "Student is already enrolled in another section of this course for the term", http.StatusConflict)
+		return
+	}
+	if enrolled >= capacity {
+		utils.ErrorResponse(w, "Section is full", http.StatusConflict)
+		return
+	}
+
+	// A student who dropped the section before gets the old row back.
+	var enrollment models.Enrollment
+	err = scanEnrollment(tx.QueryRow(`
+		INSERT INTO enrollments (section_id, student_id, status, enrolled_at)
+		VALUES ($1, $2, 'enrolled', $3)
+		ON CONFLICT (section_id, student_id) DO UPDATE
+		SET status = 'enrolled', enrolled_at = EXCLUDED.enrolled_at, dropped_at = NULL
+		RETURNING `+enrollmentColumns,
+		sectionID, enrollReq.StudentID, time.Now()), &enrollment)
+	if err != nil {
+		log.Printf("EnrollStudentHandler: error=%v", err)
+		utils.ErrorResponse(w, "Failed to enroll student", http.StatusInternalServerError)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	utils.SuccessResponse(w, enrollment, http.StatusCreated)
+}
+
+// DropEnrollmentHandler drops a student from a section, keeping the
+// enrollment with status dropped.
+func DropEnrollmentHandler(w http.ResponseWriter, r *http.Request) {
+	sectionID, err := parseIDParam(r, "id")
+	if err != nil {
+		utils.ErrorResponse(w, "Invalid section ID", http.StatusBadRequest)
+		return
+	}
+	studentID, err := parseIDParam(r, "student_id")
+	if err != nil {
+		utils.ErrorResponse(w, "Invalid student ID", http.StatusBadRequest)
+		return
+	}
+
+	result, err := database.DB.Exec(`
+		UPDATE enrollments
+		SET status = 'dropped', dropped_at = $3
+		WHERE section_id = $1 AND student_id = $2 AND status = 'enrolled'
+	`, sectionID, studentID, time.Now())
+	if err != nil {
+		log.Printf("DropEnrollmentHandler: error=%v", err)
+		utils.ErrorResponse(w, "Failed to drop student", http.StatusInternalServerError)
+		return
+	}
+
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		utils.ErrorResponse(w, "Student is not enrolled in this section", http.StatusNotFound)
+		return
+	}
+
+	utils.SuccessResponse(w, nil, http.StatusNoContent)
+}
+
+// GetSectionRosterHandler lists the students enrolled in a section.
+func GetSectionRosterHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.ErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	sectionID, err := parseIDParam(r, "id")
+	if err != nil {
+		utils.ErrorResponse(w, "Invalid section ID", http.StatusBadRequest)
+		return
+	}
+
+	section, err := getSection(database.DB, sectionID)
+	if err == sql.ErrNoRows {
+		utils.ErrorResponse(w, "Section not found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	rows, err := database.DB.Query(`
+		SELECT s.id, s.name, s.student_number, s.grade, e.enrolled_at
+		FROM enrollments e
+		JOIN students s ON s.id = e.student_id
+		WHERE e.section_id = $1 AND e.status = 'enrolled' AND s.deleted_at IS NULL
+		ORDER BY s.last_name, s.first_name, s.id
+	`, sectionID)
+	if err != nil {
+		log.Printf("GetSectionRosterHandler: query error: %v", err)
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	students := []models.RosterEntry{}
+	for rows.Next() {
+		var entry models.RosterEntry
+		if err := rows.Scan(&entry.StudentID, &entry.Name, &entry.StudentNumber, &entry.Grade, &entry.EnrolledAt); err != nil {
+			utils.ErrorResponse(w, "Error scanning results", http.StatusInternalServerError)
+			return
+		}
+		students = append(students, entry)
+	}
+
+	if err = rows.Err(); err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	utils.SuccessResponse(w, models.SectionRosterResponse{
+		Section:  section,
+		Students: students,
+		Count:    len(students),
+	}, http.StatusOK)
+}
+
+// GetStudentScheduleHandler lists the sections a student is enrolled in,
+// ordered by meeting time. The term label, term_id or term=current limit it
+// to one term.
+func GetStudentScheduleHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.ErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	studentID, err := parseIDParam(r, "id")
+	if err != nil {
+		utils.ErrorResponse(w, "Invalid student ID", http.StatusBadRequest)
+		return
+	}
+
+	if exists, err := activeRowExists(database.DB, "students", studentID); err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	} else if !exists {
+		utils.ErrorResponse(w, "Student not found", http.StatusNotFound)
+		return
+	}
+
+	conditions := []string{
+		"s.deleted_at IS NULL",
+		"s.id IN (SELECT section_id FROM enrollments WHERE student_id = $1 AND status = 'enrolled')",
+	}
+	args := []interface{}{studentID}
+	query := r.URL.Query()
+	term := strings.TrimSpace(query.Get("term"))
+	var termID *int
+	if value := query.Get("term_id"); value != "" {
+		id, err := strconv.Atoi(value)
+		if err != nil || id <= 0 {
+			utils.ErrorResponse(w, "term_id must be a positive integer", http.StatusBadRequest)
+			return
+		}
+		termID = &id
+	} else if term == "current" {
+		// The calendar decides which term is current; sections only match
+		// it through term_id.
+		current, err := calendar.CurrentTerm(database.DB, "")
+		if errors.Is(err, calendar.ErrNoTerm) {
+			utils.ErrorResponse(w, "No term is in session today", http.StatusNotFound)
+			return
+		} else if err != nil {
+			utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+			return
+		}
+		termID, term = &current.ID, ""
+	}
+	if termID != nil {
+		args = append(args, *termID)
+		conditions = append(conditions, "s.term_id = $2")
+	} else if term != "" {
+		args = append(args, term)
+		conditions = append(conditions, "s.term = $2")
+	}
+
+	rows, err := database.DB.Query(sectionSelect+`
+		`+whereClause(conditions)+`
+		ORDER BY s.term, s.start_time NULLS LAST, c.code, s.id
+	`, args...)
+	if err != nil {
+		log.Printf("GetStudentScheduleHandler: query error: %v", err)
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	sections := []models.Section{}
+	for rows.Next() {
+		var section models.Section
+		if err := scanSection(rows, &section); err != nil {
+			utils.ErrorResponse(w, "Error scanning results", http.StatusInternalServerError)
+			return
+		}
+		sections = append(sections, section)
+	}
+
+	if err = rows.Err(); err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	utils.SuccessResponse(w, models.StudentScheduleResponse{
+		StudentID: studentID,
+		Term:      term,
+		TermID:    termID,
+		Sections:  sections,
+		Count:     len(sections),
+	}, http.StatusOK)
+}
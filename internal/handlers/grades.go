@@ -0,0 +1,395 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Sea-Chels/go-practice-1/internal/auth"
+	"github.com/Sea-Chels/go-practice-1/internal/database"
+	"github.com/Sea-Chels/go-practice-1/internal/gradebook"
+	"github.com/Sea-Chels/go-practice-1/internal/models"
+	"github.com/Sea-Chels/go-practice-1/internal/utils"
+	"github.com/lib/pq"
+)
+
+const maxScoreEntries = 1000
+
+const maxScoreCommentLength = 500
+
+// assignmentScores lists the students enrolled in the assignment's section
+// with their scores, if any.
+func assignmentScores(db dbExecutor, assignment models.Assignment) ([]models.Score, error) {
+	rows, err := db.Query(`
+		SELECT st.id, st.name, sc.points, COALESCE(sc.late, FALSE), COALESCE(sc.missing, FALSE),
+		       COALESCE(sc.exempt, FALSE), sc.comment, sc.graded_by, sc.updated_at
+		FROM enrollments e
+		JOIN students st ON st.id = e.student_id
+		LEFT JOIN scores sc ON sc.assignment_id = $2 AND sc.student_id = st.id
+		WHERE e.section_id = $1 AND e.status = 'enrolled' AND st.deleted_at IS NULL
+		ORDER BY st.last_name, st.first_name, st.id
+	`, assignment.SectionID, assignment.ID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	scores := []models.Score{}
+	for rows.Next() {
+		score := models.Score{AssignmentID: assignment.ID}
+		if err := rows.Scan(&score.StudentID, &score.StudentName, &score.Points, &score.Late, &score.Missing,
+			&score.Exempt, &score.Comment, &score.GradedBy, &score.UpdatedAt); err != nil {
+			return nil, err
+		}
+		scores = append(scores, score)
+	}
+	return scores, rows.Err()
+}
+
+// GetAssignmentScoresHandler returns the scores of every student enrolled in
+// the assignment's section.
+func GetAssignmentScoresHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.ErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	assignmentID, err := parseIDParam(r, "id")
+	if err != nil {
+		utils.ErrorResponse(w, "Invalid assignment ID", http.StatusBadRequest)
+		return
+	}
+
+	assignment, err := getAssignment(database.DB, assignmentID)
+	if err == sql.ErrNoRows {
+		utils.ErrorResponse(w, "Assignment not found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	scores, err := assignmentScores(database.DB, assignment)
+	if err != nil {
+		log.Printf("GetAssignmentScoresHandler: error=%v", err)
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	utils.SuccessResponse(w, models.AssignmentScoresResponse{
+		Assignment: assignment,
+		Scores:     scores,
+		Count:      len(scores),
+	}, http.StatusOK)
+}
+
+func validateScoreEntries(req *models.RecordScoresRequest, maxPoints float64) error {
+	if len(req.Scores) == 0 || len(req.Scores) > maxScoreEntries {
+		return fmt.Errorf("scores must contain between 1 and %d items", maxScoreEntries)
+	}
+
+	seen := map[int]bool{}
+	for i := range req.Scores {
+		entry := &req.Scores[i]
+		entry.Comment = strings.TrimSpace(entry.Comment)
+
+		if entry.StudentID <= 0 {
+			return fmt.Errorf("scores[%d].student_id is required", i)
+		}
+		if seen[entry.StudentID] {
+			return fmt.Errorf("scores[%d].student_id is listed more than once", i)
+		}
+		seen[entry.StudentID] = true
+		if entry.Points != nil && (*entry.Points < 0 || *entry.Points > maxPoints) {
+			return fmt.Errorf("scores[%d].points must be between 0 and %g", i, maxPoints)
+		}
+		if entry.Missing && entry.Exempt {
+			return fmt.Errorf("scores[%d] cannot be both missing and exempt", i)
+		}
+		if entry.Missing && entry.Points != nil {
+			return fmt.Errorf("scores[%d] cannot have points when missing", i)
+		}
+		if err := utils.ValidateMaxLength("comment", entry.Comment, maxScoreCommentLength); err != nil {
+			return fmt.Errorf("scores[%d].%v", i, err)
+		}
+	}
+	return nil
+}
+
+// RecordScoresHandler enters or replaces the scores of the listed students,
+// who must be enrolled in the assignment's section. Students not listed keep
+// their scores. All entries are saved together or not at all.
+func RecordScoresHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		utils.ErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	claims, _ := auth.GetUserFromContext(r.Context())
+
+	assignmentID, err := parseIDParam(r, "id")
+	if err != nil {
+		utils.ErrorResponse(w, "Invalid assignment ID", http.StatusBadRequest)
+		return
+	}
+
+	var scoresReq models.RecordScoresRequest
+	if err := json.NewDecoder(r.Body).Decode(&scoresReq); err != nil {
+		utils.ErrorResponse(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	tx, err := database.DB.Begin()
+	if err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	// The shared lock keeps max_points from changing meanwhile.
+	if _, err := tx.Exec(`SELECT 1 FROM assignments WHERE id = $1 FOR SHARE`, assignmentID); err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	assignment, err := getAssignment(tx, assignmentID)
+	if err == sql.ErrNoRows {
+		utils.ErrorResponse(w, "Assignment not found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	if err := validateScoreEntries(&scoresReq, assignment.MaxPoints); err != nil {
+		utils.ErrorResponse(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	studentIDs := make([]int, len(scoresReq.Scores))
+	for i, entry := range scoresReq.Scores {
+		studentIDs[i] = entry.StudentID
+	}
+	rows, err := tx.Query(`
+		SELECT student_id FROM enrollments
+		WHERE section_id = $1 AND status = 'enrolled' AND student_id = ANY($2::int[])
+	`, assignment.SectionID, pq.Array(studentIDs))
+	if err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	enrolled := map[int]bool{}
+	for rows.Next() {
+		var studentID int
+		if err := rows.Scan(&studentID); err != nil {
+			rows.Close()
+			utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+			return
+		}
+		enrolled[studentID] = true
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	now := time.Now()
+	for i, entry := range scoresReq.Scores {
+		if !enrolled[entry.StudentID] {
+			utils.ErrorResponse(w, fmt.Sprintf("scores[%d].student_id is not enrolled in the section", i), http.StatusBadRequest)
+			return
+		}
+
+		_, err := tx.Exec(`
+			INSERT INTO scores (assignment_id, student_id, points, late, missing, exempt, comment, graded_by,
+			                    created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $9)
+			ON CONFLICT (assignment_id, student_id) DO UPDATE
+			SET points = EXCLUDED.points, late = EXCLUDED.late, missing = EXCLUDED.missing,
+			    exempt = EXCLUDED.exempt, comment = EXCLUDED.comment, graded_by = EXCLUDED.graded_by,
+			    updated_at = EXCLUDED.updated_at
+		`, assignmentID, entry.StudentID, entry.Points, entry.Late, entry.Missing, entry.Exempt,
+			nullableString(entry.Comment), actorID(claims), now)
+		if err != nil {
+			log.Printf("RecordScoresHandler: error=%v", err)
+			utils.ErrorResponse(w, "Failed to record scores", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	scores, err := assignmentScores(tx, assignment)
+	if err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	utils.SuccessResponse(w, models.AssignmentScoresResponse{
+		Assignment: assignment,
+		Scores:     scores,
+		Count:      len(scores),
+	}, http.StatusOK)
+}
+
+// GetSectionGradesHandler returns the running average and letter grade of
+// every student enrolled in a section.
+func GetSectionGradesHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.ErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	sectionID, err := parseIDParam(r, "id")
+	if err != nil {
+		utils.ErrorResponse(w, "Invalid section ID", http.StatusBadRequest)
+		return
+	}
+
+	section, err := getSection(database.DB, sectionID)
+	if err == sql.ErrNoRows {
+		utils.ErrorResponse(w, "Section not found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	book, err := gradebook.Load(database.DB, sectionID)
+	if err != nil {
+		log.Printf("GetSectionGradesHandler: error=%v", err)
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	rows, err := database.DB.Query(`
+		SELECT st.id, st.name
+		FROM enrollments e
+		JOIN students st ON st.id = e.student_id
+		WHERE e.section_id = $1 AND e.status = 'enrolled' AND st.deleted_at IS NULL
+		ORDER BY st.last_name, st.first_name, st.id
+	`, sectionID)
+	if err != nil {
+		log.Printf("GetSectionGradesHandler: query error: %v", err)
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	students := []models.StudentGrade{}
+	for rows.Next() {
+		var studentID int
+		var name string
+		if err := rows.Scan(&studentID, &name); err != nil {
+			utils.ErrorResponse(w, "Error scanning results", http.StatusInternalServerError)
+			return
+		}
+		grade := book.Grade(studentID)
+		grade.StudentName = name
+		students = append(students, grade)
+	}
+
+	if err = rows.Err(); err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	utils.SuccessResponse(w, models.SectionGradesResponse{
+		Section:      section,
+		GradingScale: book.Scale,
+		Students:     students,
+		Count:        len(students),
+	}, http.StatusOK)
+}
+
+// GetStudentGradesHandler returns a student's running average in each
+// section they are enrolled in, optionally for one term_id.
+func GetStudentGradesHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.ErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	studentID, err := parseIDParam(r, "id")
+	if err != nil {
+		utils.ErrorResponse(w, "Invalid student ID", http.StatusBadRequest)
+		return
+	}
+
+	conditions := []string{
+		"s.deleted_at IS NULL",
+		"s.id IN (SELECT section_id FROM enrollments WHERE student_id = $1 AND status = 'enrolled')",
+	}
+	args := []interface{}{studentID}
+	if value := r.URL.Query().Get("term_id"); value != "" {
+		termID, err := strconv.Atoi(value)
+		if err != nil || termID <= 0 {
+			utils.ErrorResponse(w, "term_id must be a positive integer", http.StatusBadRequest)
+			return
+		}
+		args = append(args, termID)
+		conditions = append(conditions, "s.term_id = $2")
+	}
+
+	if exists, err := activeRowExists(database.DB, "students", studentID); err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	} else if !exists {
+		utils.ErrorResponse(w, "Student not found", http.StatusNotFound)
+		return
+	}
+
+	rows, err := database.DB.Query(sectionSelect+`
+		`+whereClause(conditions)+`
+		ORDER BY s.term, c.code, s.id
+	`, args...)
+	if err != nil {
+		log.Printf("GetStudentGradesHandler: query error: %v", err)
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	var sections []models.Section
+	for rows.Next() {
+		var section models.Section
+		if err := scanSection(rows, &section); err != nil {
+			rows.Close()
+			utils.ErrorResponse(w, "Error scanning results", http.StatusInternalServerError)
+			return
+		}
+		sections = append(sections, section)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	grades := []models.StudentGrade{}
+	for _, section := range sections {
+		book, err := gradebook.Load(database.DB, section.ID)
+		if err != nil {
+			log.Printf("GetStudentGradesHandler: error=%v", err)
+			utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+			return
+		}
+		grade := book.Grade(studentID)
+		grade.CourseCode = section.CourseCode
+		grade.CourseName = section.CourseName
+		grades = append(grades, grade)
+	}
+
+	utils.SuccessResponse(w, models.StudentGradesResponse{
+		StudentID: studentID,
+		Sections:  grades,
+		Count:     len(grades),
+	}, http.StatusOK)
+}
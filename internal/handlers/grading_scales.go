@@ -0,0 +1,280 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Sea-Chels/go-practice-1/internal/database"
+	"github.com/Sea-Chels/go-practice-1/internal/gradebook"
+	"github.com/Sea-Chels/go-practice-1/internal/models"
+	"github.com/Sea-Chels/go-practice-1/internal/utils"
+)
+
+const maxGradeBands = 20
+
+// validateGradingScaleRequest checks the bands and sorts them from the
+// highest down. A band starting at 0 is required so every average gets a
+// letter.
+func validateGradingScaleRequest(req *models.CreateGradingScaleRequest) error {
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if err := utils.ValidateMaxLength("name", req.Name, 50); err != nil {
+		return err
+	}
+	if len(req.Bands) == 0 || len(req.Bands) > maxGradeBands {
+		return fmt.Errorf("bands must contain between 1 and %d items", maxGradeBands)
+	}
+
+	letters := map[string]bool{}
+	minimums := map[float64]bool{}
+	for i := range req.Bands {
+		band := &req.Bands[i]
+		band.Letter = strings.TrimSpace(band.Letter)
+		if band.Letter == "" || len(band.Letter) > 5 {
+			return fmt.Errorf("bands[%d].letter is required and must not exceed 5 characters", i)
+		}
+		if letters[band.Letter] {
+			return fmt.Errorf("bands[%d].letter is used more than once", i)
+		}
+		if band.MinPercent < 0 || band.MinPercent > 100 {
+			return fmt.Errorf("bands[%d].min_percent must be between 0 and 100", i)
+		}
+		if minimums[band.MinPercent] {
+			return fmt.Errorf("bands[%d].min_percent is used more than once", i)
+		}
+		letters[band.Letter] = true
+		minimums[band.MinPercent] = true
+	}
+	if !minimums[0] {
+		return fmt.Errorf("bands must include one with min_percent 0")
+	}
+
+	sort.Slice(req.Bands, func(i, j int) bool { return req.Bands[i].MinPercent > req.Bands[j].MinPercent })
+	return nil
+}
+
+func GetGradingScalesHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.ErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	rows, err := database.DB.Query(`SELECT ` + gradebook.ScaleColumns + ` FROM grading_scales ORDER BY is_default DESC, name`)
+	if err != nil {
+		log.Printf("GetGradingScalesHandler: query error: %v", err)
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	scales := []models.GradingScale{}
+	for rows.Next() {
+		var scale models.GradingScale
+		if err := gradebook.ScanScale(rows, &scale); err != nil {
+			utils.ErrorResponse(w, "Error scanning results", http.StatusInternalServerError)
+			return
+		}
+		scales = append(scales, scale)
+	}
+
+	if err = rows.Err(); err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	utils.SuccessResponse(w, models.GradingScalesResponse{GradingScales: scales, Count: len(scales)}, http.StatusOK)
+}
+
+func GetGradingScaleHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.ErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	scaleID, err := parseIDParam(r, "id")
+	if err != nil {
+		utils.ErrorResponse(w, "Invalid grading scale ID", http.StatusBadRequest)
+		return
+	}
+
+	var scale models.GradingScale
+	err = gradebook.ScanScale(database.DB.QueryRow(`
+		SELECT `+gradebook.ScaleColumns+` FROM grading_scales WHERE id = $1
+	`, scaleID), &scale)
+	if err == sql.ErrNoRows {
+		utils.ErrorResponse(w, "Grading scale not found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	utils.SuccessResponse(w, scale, http.StatusOK)
+}
+
+func CreateGradingScaleHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.ErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var createReq models.CreateGradingScaleRequest
+	if err := json.NewDecoder(r.Body).Decode(&createReq); err != nil {
+		utils.ErrorResponse(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := validateGradingScaleRequest(&createReq); err != nil {
+		utils.ErrorResponse(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	writeGradingScale(w, 0, createReq)
+}
+
+// UpdateGradingScaleHandler replaces a scale. The default scale stays the
+// default until another one is made the default.
+func UpdateGradingScaleHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		utils.ErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	scaleID, err := parseIDParam(r, "id")
+	if err != nil {
+		utils.ErrorResponse(w, "Invalid grading scale ID", http.StatusBadRequest)
+		return
+	}
+
+	var updateReq models.CreateGradingScaleRequest
+	if err := json.NewDecoder(r.Body).Decode(&updateReq); err != nil {
+		utils.ErrorResponse(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := validateGradingScaleRequest(&updateReq); err != nil {
+		utils.ErrorResponse(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	writeGradingScale(w, scaleID, updateReq)
+}
+
+// writeGradingScale creates the scale when scaleID is 0 and updates it
+// otherwise. Making a scale the default takes that over from the previous
+// default.
+func writeGradingScale(w http.ResponseWriter, scaleID int, req models.CreateGradingScaleRequest) {
+	bands, err := json.Marshal(req.Bands)
+	if err != nil {
+		utils.ErrorResponse(w, "Failed to save grading scale", http.StatusInternalServerError)
+		return
+	}
+
+	tx, err := database.DB.Begin()
+	if err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	// Writers take turns, so two requests making different scales the
+	// default cannot both clear the old one and then collide.
+	if _, err := tx.Exec(`LOCK TABLE grading_scales IN SHARE ROW EXCLUSIVE MODE`); err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	if scaleID != 0 {
+		var isDefault bool
+		err := tx.QueryRow(`SELECT is_default FROM grading_scales WHERE id = $1 FOR UPDATE`, scaleID).Scan(&isDefault)
+		if err == sql.ErrNoRows {
+			utils.ErrorResponse(w, "Grading scale not found", http.StatusNotFound)
+			return
+		} else if err != nil {
+			utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+			return
+		}
+		if isDefault && !req.IsDefault {
+			utils.ErrorResponse(w, "This is the default grading scale; make another scale the default first", http.StatusConflict)
+			return
+		}
+	}
+
+	if req.IsDefault {
+		if _, err := tx.Exec(`UPDATE grading_scales SET is_default = FALSE WHERE is_default AND id <> $1`, scaleID); err != nil {
+			utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	var scale models.GradingScale
+	status := http.StatusOK
+	if scaleID == 0 {
+		status = http.StatusCreated
+		err = gradebook.ScanScale(tx.QueryRow(`
+			INSERT INTO grading_scales (name, is_default, bands, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $4)
+			RETURNING `+gradebook.ScaleColumns,
+			req.Name, req.IsDefault, string(bands), time.Now()), &scale)
+	} else {
+		err = gradebook.ScanScale(tx.QueryRow(`
+			UPDATE grading_scales
+			SET name = $2, is_default = $3, bands = $4, updated_at = $5
+			WHERE id = $1
+			RETURNING `+gradebook.ScaleColumns,
+			scaleID, req.Name, req.IsDefault, string(bands), time.Now()), &scale)
+	}
+
+	if isUniqueViolation(err) {
+		utils.ErrorResponse(w, "A grading scale with this name already exists", http.StatusConflict)
+		return
+	} else if err != nil {
+		log.Printf("writeGradingScale: error=%v", err)
+		utils.ErrorResponse(w, "Failed to save grading scale", http.StatusInternalServerError)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	utils.SuccessResponse(w, scale, status)
+}
+
+// DeleteGradingScaleHandler deletes a scale other than the default. Sections
+// using it fall back to the default scale.
+func DeleteGradingScaleHandler(w http.ResponseWriter, r *http.Request) {
+	scaleID, err := parseIDParam(r, "id")
+	if err != nil {
+		utils.ErrorResponse(w, "Invalid grading scale ID", http.StatusBadRequest)
+		return
+	}
+
+	result, err := database.DB.Exec(`DELETE FROM grading_scales WHERE id = $1 AND NOT is_default`, scaleID)
+	if err != nil {
+		log.Printf("DeleteGradingScaleHandler: error=%v", err)
+		utils.ErrorResponse(w, "Failed to delete grading scale", http.StatusInternalServerError)
+		return
+	}
+
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		var exists bool
+		if err := database.DB.QueryRow(`SELECT EXISTS(SELECT 1 FROM grading_scales WHERE id = $1)`, scaleID).Scan(&exists); err == nil && exists {
+			utils.ErrorResponse(w, "The default grading scale cannot be deleted", http.StatusConflict)
+			return
+		}
+		utils.ErrorResponse(w, "Grading scale not found", http.StatusNotFound)
+		return
+	}
+
+	utils.SuccessResponse(w, nil, http.StatusNoContent)
+}
@@ -0,0 +1,545 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Sea-Chels/go-practice-1/internal/database"
+	"github.com/Sea-Chels/go-practice-1/internal/models"
+	"github.com/Sea-Chels/go-practice-1/internal/utils"
+)
+
+const guardianColumns = `id, first_name, last_name, email, phone, alternate_phone, created_at, updated_at, deleted_at`
+
+const guardianLinkColumns = `relationship, has_custody, can_pickup, emergency_contact, priority`
+
+func scanGuardian(row interface{ Scan(...interface{}) error }, guardian *models.Guardian, extra ...interface{}) error {
+	return row.Scan(append([]interface{}{
+		&guardian.ID, &guardian.FirstName, &guardian.LastName, &guardian.Email, &guardian.Phone,
+		&guardian.AlternatePhone, &guardian.CreatedAt, &guardian.UpdatedAt, &guardian.DeletedAt,
+	}, extra...)...)
+}
+
+func guardianLinkFields(link *models.GuardianLink) []interface{} {
+	return []interface{}{&link.Relationship, &link.HasCustody, &link.CanPickup, &link.EmergencyContact, &link.Priority}
+}
+
+func validateGuardianRequest(req *models.CreateGuardianRequest) error {
+	req.FirstName = strings.TrimSpace(req.FirstName)
+	req.LastName = strings.TrimSpace(req.LastName)
+	req.Email = strings.TrimSpace(req.Email)
+	req.Phone = strings.TrimSpace(req.Phone)
+	req.AlternatePhone = strings.TrimSpace(req.AlternatePhone)
+
+	if err := utils.ValidateFirstName(req.FirstName); err != nil {
+		return err
+	}
+	if err := utils.ValidateLastName(req.LastName); err != nil {
+		return err
+	}
+	if req.Email != "" {
+		if err := utils.ValidateEmail(req.Email); err != nil {
+			return err
+		}
+	}
+	if err := utils.ValidatePhone("phone", req.Phone); err != nil {
+		return err
+	}
+	if req.AlternatePhone != "" {
+		return utils.ValidatePhone("alternate_phone", req.AlternatePhone)
+	}
+	return nil
+}
+
+func GetGuardiansHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.ErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := `SELECT ` + guardianColumns + ` FROM guardians WHERE deleted_at IS NULL ORDER BY last_name, first_name, id`
+	if r.URL.Query().Get("include_deleted") == "true" {
+		query = `SELECT ` + guardianColumns + ` FROM guardians ORDER BY last_name, first_name, id`
+	}
+
+	rows, err := database.DB.Query(query)
+	if err != nil {
+		log.Printf("GetGuardiansHandler: query error: %v", err)
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	guardians := []models.Guardian{}
+	for rows.Next() {
+		var guardian models.Guardian
+		if err := scanGuardian(rows, &guardian); err != nil {
+			utils.ErrorResponse(w, "Error scanning results", http.StatusInternalServerError)
+			return
+		}
+		guardians = append(guardians, guardian)
+	}
+
+	if err = rows.Err(); err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	utils.SuccessResponse(w, models.GuardiansResponse{Guardians: guardians, Count: len(guardians)}, http.StatusOK)
+}
+
+func GetGuardianHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.ErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	guardianID, err := parseIDParam(r, "id")
+	if err != nil {
+		utils.ErrorResponse(w, "Invalid guardian ID", http.StatusBadRequest)
+		return
+	}
+
+	var guardian models.Guardian
+	err = scanGuardian(database.DB.QueryRow(`
+		SELECT `+guardianColumns+`
+		FROM guardians
+		WHERE id = $1 AND deleted_at IS NULL
+	`, guardianID), &guardian)
+
+	if err == sql.ErrNoRows {
+		utils.ErrorResponse(w, "Guardian not found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	utils.SuccessResponse(w, guardian, http.StatusOK)
+}
+
+func CreateGuardianHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.ErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var createReq models.CreateGuardianRequest
+	if err := json.NewDecoder(r.Body).Decode(&createReq); err != nil {
+		utils.ErrorResponse(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := validateGuardianRequest(&createReq); err != nil {
+		utils.ErrorResponse(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var guardian models.Guardian
+	now := time.Now()
+	err := scanGuardian(database.DB.QueryRow(`
+		INSERT INTO guardians (first_name, last_name, email, phone, alternate_phone, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $6)
+		RETURNING `+guardianColumns,
+		createReq.FirstName, createReq.LastName, nullableString(createReq.Email), createReq.Phone,
+		nullableString(createReq.AlternatePhone), now), &guardian)
+	if err != nil {
+		log.Printf("CreateGuardianHandler: error=%v", err)
+		utils.ErrorResponse(w, "Failed to create guardian", http.StatusInternalServerError)
+		return
+	}
+
+	utils.SuccessResponse(w, guardian, http.StatusCreated)
+}
+
+func UpdateGuardianHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		utils.ErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	guardianID, err := parseIDParam(r, "id")
+	if err != nil {
+		utils.ErrorResponse(w, "Invalid guardian ID", http.StatusBadRequest)
+		return
+	}
+
+	var updateReq models.CreateGuardianRequest
+	if err := json.NewDecoder(r.Body).Decode(&updateReq); err != nil {
+		utils.ErrorResponse(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := validateGuardianRequest(&updateReq); err != nil {
+		utils.ErrorResponse(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var guardian models.Guardian
+	err = scanGuardian(database.DB.QueryRow(`
+		UPDATE guardians
+		SET first_name = $2, last_name = $3, email = $4, phone = $5, alternate_phone = $6, updated_at = $7
+		WHERE id = $1 AND deleted_at IS NULL
+		RETURNING `+guardianColumns,
+		guardianID, updateReq.FirstName, updateReq.LastName, nullableString(updateReq.Email), updateReq.Phone,
+		nullableString(updateReq.AlternatePhone), time.Now()), &guardian)
+
+	if err == sql.ErrNoRows {
+		utils.ErrorResponse(w, "Guardian not found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		log.Printf("UpdateGuardianHandler: error=%v", err)
+		utils.ErrorResponse(w, "Failed to update guardian", http.StatusInternalServerError)
+		return
+	}
+
+	utils.SuccessResponse(w, guardian, http.StatusOK)
+}
+
+// DeleteGuardianHandler soft deletes a guardian. Its links are kept but it is
+// no longer listed for its students.
+func DeleteGuardianHandler(w http.ResponseWriter, r *http.Request) {
+	guardianID, err := parseIDParam(r, "id")
+	if err != nil {
+		utils.ErrorResponse(w, "Invalid guardian ID", http.StatusBadRequest)
+		return
+	}
+
+	now := time.Now()
+	result, err := database.DB.Exec(`
+		UPDATE guardians
+		SET deleted_at = $2, updated_at = $3
+		WHERE id = $1 AND deleted_at IS NULL
+	`, guardianID, now, now)
+	if err != nil {
+		log.Printf("DeleteGuardianHandler: error=%v", err)
+		utils.ErrorResponse(w, "Failed to delete guardian", http.StatusInternalServerError)
+		return
+	}
+
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		utils.ErrorResponse(w, "Guardian not found", http.StatusNotFound)
+		return
+	}
+
+	utils.SuccessResponse(w, nil, http.StatusNoContent)
+}
+
+// GetStudentGuardiansHandler lists a student's guardians in the order they
+// should be contacted.
+func GetStudentGuardiansHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.ErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	studentID, err := parseIDParam(r, "id")
+	if err != nil {
+		utils.ErrorResponse(w, "Invalid student ID", http.StatusBadRequest)
+		return
+	}
+
+	if exists, err := activeRowExists(database.DB, "students", studentID); err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	} else if !exists {
+		utils.ErrorResponse(w, "Student not found", http.StatusNotFound)
+		return
+	}
+
+	rows, err := database.DB.Query(`
+		SELECT `+qualifiedColumns("g", guardianColumns)+`, `+qualifiedColumns("sg", guardianLinkColumns)+`
+		FROM student_guardians sg
+		JOIN guardians g ON g.id = sg.guardian_id
+		WHERE sg.student_id = $1 AND g.deleted_at IS NULL
+		ORDER BY sg.priority, g.last_name, g.first_name, g.id
+	`, studentID)
+	if err != nil {
+		log.Printf("GetStudentGuardiansHandler: query error: %v", err)
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	guardians := []models.StudentGuardian{}
+	for rows.Next() {
+		var guardian models.StudentGuardian
+		if err := scanGuardian(rows, &guardian.Guardian, guardianLinkFields(&guardian.GuardianLink)...); err != nil {
+			utils.ErrorResponse(w, "Error scanning results", http.StatusInternalServerError)
+			return
+		}
+		guardians = append(guardians, guardian)
+	}
+
+	if err = rows.Err(); err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	utils.SuccessResponse(w, models.StudentGuardiansResponse{
+		StudentID: studentID,
+		Guardians: guardians,
+		Count:     len(guardians),
+	}, http.StatusOK)
+}
+
+// GetGuardianStudentsHandler lists the students a guardian is linked to.
+func GetGuardianStudentsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.ErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	guardianID, err := parseIDParam(r, "id")
+	if err != nil {
+		utils.ErrorResponse(w, "Invalid guardian ID", http.StatusBadRequest)
+		return
+	}
+
+	if exists, err := activeRowExists(database.DB, "guardians", guardianID); err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	} else if !exists {
+		utils.ErrorResponse(w, "Guardian not found", http.StatusNotFound)
+		return
+	}
+
+	rows, err := database.DB.Query(`
+		SELECT s.id, s.name, s.grade, s.status, `+qualifiedColumns("sg", guardianLinkColumns)+`
+		FROM student_guardians sg
+		JOIN students s ON s.id = sg.student_id
+		WHERE sg.guardian_id = $1 AND s.deleted_at IS NULL
+		ORDER BY s.name, s.id
+	`, guardianID)
+	if err != nil {
+		log.Printf("GetGuardianStudentsHandler: query error: %v", err)
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	students := []models.GuardianStudent{}
+	for rows.Next() {
+		var student models.GuardianStudent
+		dest := append([]interface{}{&student.StudentID, &student.Name, &student.Grade, &student.Status},
+			guardianLinkFields(&student.GuardianLink)...)
+		if err := rows.Scan(dest...); err != nil {
+			utils.ErrorResponse(w, "Error scanning results", http.StatusInternalServerError)
+			return
+		}
+		students = append(students, student)
+	}
+
+	if err = rows.Err(); err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	utils.SuccessResponse(w, models.GuardianStudentsResponse{
+		GuardianID: guardianID,
+		Students:   students,
+		Count:      len(students),
+	}, http.StatusOK)
+}
+
+// LinkGuardianHandler creates or replaces the link between a student and a
+// guardian. It responds 201 when the link is new.
+func LinkGuardianHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		utils.ErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	studentID, err := parseIDParam(r, "id")
+	if err != nil {
+		utils.ErrorResponse(w, "Invalid student ID", http.StatusBadRequest)
+		return
+	}
+	guardianID, err := parseIDParam(r, "guardian_id")
+	if err != nil {
+		utils.ErrorResponse(w, "Invalid guardian ID", http.StatusBadRequest)
+		return
+	}
+
+	var linkReq models.LinkGuardianRequest
+	if err := json.NewDecoder(r.Body).Decode(&linkReq); err != nil {
+		utils.ErrorResponse(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	link := models.GuardianLink{
+		Relationship:     strings.TrimSpace(linkReq.Relationship),
+		HasCustody:       linkReq.HasCustody != nil && *linkReq.HasCustody,
+		CanPickup:        linkReq.CanPickup != nil && *linkReq.CanPickup,
+		EmergencyContact: linkReq.EmergencyContact == nil || *linkReq.EmergencyContact,
+	}
+	if err := utils.ValidateGuardianRelationship(link.Relationship); err != nil {
+		utils.ErrorResponse(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if linkReq.Priority != nil && *linkReq.Priority < 1 {
+		utils.ErrorResponse(w, "priority must be at least 1", http.StatusBadRequest)
+		return
+	}
+
+	tx, err := database.DB.Begin()
+	if err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	// Locking the student serializes changes to its contact order.
+	var exists bool
+	err = tx.QueryRow(`
+		SELECT EXISTS (SELECT 1 FROM guardians WHERE id = $2 AND deleted_at IS NULL)
+		FROM students
+		WHERE id = $1 AND deleted_at IS NULL
+		FOR UPDATE
+	`, studentID, guardianID).Scan(&exists)
+	if err == sql.ErrNoRows || (err == nil && !exists) {
+		utils.ErrorResponse(w, "Student or guardian not found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	if linkReq.Priority != nil {
+		link.Priority = *linkReq.Priority
+		err = makeGuardianPriorityRoom(tx, studentID, guardianID, link.Priority)
+	} else {
+		// Without a priority an existing link keeps its place and a new one
+		// goes last.
+		err = tx.QueryRow(`
+			SELECT COALESCE(
+				(SELECT priority FROM student_guardians WHERE student_id = $1 AND guardian_id = $2),
+				(SELECT COALESCE(MAX(priority), 0) + 1 FROM student_guardians WHERE student_id = $1)
+			)
+		`, studentID, guardianID).Scan(&link.Priority)
+	}
+	if err != nil {
+		log.Printf("LinkGuardianHandler: error=%v", err)
+		utils.ErrorResponse(w, "Failed to link guardian", http.StatusInternalServerError)
+		return
+	}
+
+	// xmax is zero only for rows inserted by this statement.
+	var created bool
+	err = tx.QueryRow(`
+		INSERT INTO student_guardians (student_id, guardian_id, `+guardianLinkColumns+`, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $8)
+		ON CONFLICT (student_id, guardian_id) DO UPDATE
+		SET relationship = EXCLUDED.relationship, has_custody = EXCLUDED.has_custody,
+		    can_pickup = EXCLUDED.can_pickup, emergency_contact = EXCLUDED.emergency_contact,
+		    priority = EXCLUDED.priority, updated_at = EXCLUDED.updated_at
+		RETURNING xmax = 0
+	`, studentID, guardianID, link.Relationship, link.HasCustody, link.CanPickup,
+		link.EmergencyContact, link.Priority, time.Now()).Scan(&created)
+	if err != nil {
+		log.Printf("LinkGuardianHandler: error=%v", err)
+		utils.ErrorResponse(w, "Failed to link guardian", http.StatusInternalServerError)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	status := http.StatusOK
+	if created {
+		status = http.StatusCreated
+	}
+	utils.SuccessResponse(w, link, status)
+}
+
+// makeGuardianPriorityRoom frees priority for guardianID among the
+// student's guardians by moving the contacts from priority onwards down one
+// place, up to the first gap. The guardian's own link is first parked after
+// all others, and contacts are moved from the bottom up, so every step keeps
+// priorities unique.
+func makeGuardianPriorityRoom(db dbExecutor, studentID, guardianID, priority int) error {
+	if _, err := db.Exec(`
+		UPDATE student_guardians
+		SET priority = (SELECT MAX(priority) + 1 FROM student_guardians WHERE student_id = $1)
+		WHERE student_id = $1 AND guardian_id = $2 AND priority <> $3
+	`, studentID, guardianID, priority); err != nil {
+		return err
+	}
+
+	rows, err := db.Query(`
+		SELECT guardian_id, priority
+		FROM student_guardians
+		WHERE student_id = $1 AND priority >= $2
+		ORDER BY priority
+	`, studentID, priority)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var shifted []int
+	next := priority
+	for rows.Next() {
+		var id, current int
+		if err := rows.Scan(&id, &current); err != nil {
+			return err
+		}
+		// Stop at the first gap, or straight away if the guardian already
+		// holds the priority.
+		if current != next || (id == guardianID && current == priority) {
+			break
+		}
+		shifted = append(shifted, id)
+		next++
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	rows.Close()
+
+	now := time.Now()
+	for i := len(shifted) - 1; i >= 0; i-- {
+		if _, err := db.Exec(`
+			UPDATE student_guardians SET priority = priority + 1, updated_at = $3
+			WHERE student_id = $1 AND guardian_id = $2
+		`, studentID, shifted[i], now); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func UnlinkGuardianHandler(w http.ResponseWriter, r *http.Request) {
+	studentID, err := parseIDParam(r, "id")
+	if err != nil {
+		utils.ErrorResponse(w, "Invalid student ID", http.StatusBadRequest)
+		return
+	}
+	guardianID, err := parseIDParam(r, "guardian_id")
+	if err != nil {
+		utils.ErrorResponse(w, "Invalid guardian ID", http.StatusBadRequest)
+		return
+	}
+
+	result, err := database.DB.Exec(`
+		DELETE FROM student_guardians WHERE student_id = $1 AND guardian_id = $2
+	`, studentID, guardianID)
+	if err != nil {
+		log.Printf("UnlinkGuardianHandler: error=%v", err)
+		utils.ErrorResponse(w, "Failed to unlink guardian", http.StatusInternalServerError)
+		return
+	}
+
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		utils.ErrorResponse(w, "Guardian is not linked to this student", http.StatusNotFound)
+		return
+	}
+
+	utils.SuccessResponse(w, nil, http.StatusNoContent)
+}
@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/Sea-Chels/go-practice-1/internal/auth"
+	"github.com/gorilla/mux"
+	"github.com/lib/pq"
+)
+
+// dbExecutor is satisfied by both *sql.DB and *sql.Tx.
+type dbExecutor interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+// parseIDParam reads a positive integer ID from the named route variable.
+func parseIDParam(r *http.Request, name string) (int, error) {
+	id, err := strconv.Atoi(mux.Vars(r)[name])
+	if err != nil || id <= 0 {
+		return 0, fmt.Errorf("invalid %s", name)
+	}
+	return id, nil
+}
+
+// isUniqueViolation reports whether err is a Postgres unique constraint violation.
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
+
+// nullableString maps an empty string to SQL NULL.
+func nullableString(value string) interface{} {
+	if value == "" {
+		return nil
+	}
+	return value
+}
+
+func stringValue(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
+
+// activeRowExists reports whether table holds a row with the ID that is not
+// soft deleted. table must be a constant, never user input.
+func activeRowExists(db dbExecutor, table string, id int) (bool, error) {
+	var exists bool
+	err := db.QueryRow(`SELECT EXISTS(SELECT 1 FROM `+table+` WHERE id = $1 AND deleted_at IS NULL)`, id).Scan(&exists)
+	return exists, err
+}
+
+// qualifiedColumns prefixes every column in a comma separated list with
+// alias, for queries that join tables sharing column names.
+func qualifiedColumns(alias, columns string) string {
+	names := strings.Split(columns, ",")
+	for i, name := range names {
+		names[i] = alias + "." + strings.TrimSpace(name)
+	}
+	return strings.Join(names, ", ")
+}
+
+// envInt reads a positive integer from the environment, falling back when
+// the variable is unset or invalid.
+func envInt(key string, fallback int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil || value <= 0 {
+		return fallback
+	}
+	return value
+}
+
+// actorID returns the user to attribute an action to: the signed-in user, or
+// for API keys the key's creator. It is nil when there are no claims.
+func actorID(claims *auth.Claims) *int {
+	if claims == nil || claims.UserID == 0 {
+		return nil
+	}
+	id := claims.UserID
+	return &id
+}
+
+// mergePatch applies an RFC 7396 JSON Merge Patch to a JSON document.
+func mergePatch(target, patch []byte) ([]byte, error) {
+	var targetValue, patchValue interface{}
+	if err := json.Unmarshal(target, &targetValue); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(patch, &patchValue); err != nil {
+		return nil, err
+	}
+	return json.Marshal(applyMergePatch(targetValue, patchValue))
+}
+
+func applyMergePatch(target, patch interface{}) interface{} {
+	patchObject, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+
+	targetObject, ok := target.(map[string]interface{})
+	if !ok {
+		targetObject = map[string]interface{}{}
+	}
+	for key, value := range patchObject {
+		if value == nil {
+			delete(targetObject, key)
+			continue
+		}
+		targetObject[key] = applyMergePatch(targetObject[key], value)
+	}
+	return targetObject
+}
+
+// decodeStrict decodes data into v, rejecting unknown fields.
+func decodeStrict(data []byte, v interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	return decoder.Decode(v)
+}
+
+// isMergePatch reports whether the request body is declared as a JSON Merge
+// Patch. Plain application/json is accepted too for convenience.
+func isMergePatch(r *http.Request) bool {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	return err == nil && (mediaType == "application/merge-patch+json" || mediaType == "application/json")
+}
+
+// etagMatches reports whether an If-Match or If-None-Match header lists etag
+// or is "*". If-Match requires strong comparison, so weak validators (W/)
+// only match when weak is true.
+func etagMatches(header, etag string, weak bool) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if weak {
+			candidate = strings.TrimPrefix(candidate, "W/")
+		}
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
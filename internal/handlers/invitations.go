@@ -0,0 +1,281 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Sea-Chels/go-practice-1/internal/auth"
+	"github.com/Sea-Chels/go-practice-1/internal/database"
+	"github.com/Sea-Chels/go-practice-1/internal/models"
+	"github.com/Sea-Chels/go-practice-1/internal/utils"
+)
+
+const invitationColumns = `id, email, full_name, role, invited_by, expires_at, accepted_at, revoked_at, created_at`
+
+func scanInvitation(row interface{ Scan(...interface{}) error }, invitation *models.Invitation) error {
+	return row.Scan(&invitation.ID, &invitation.Email, &invitation.FullName, &invitation.Role,
+		&invitation.InvitedBy, &invitation.ExpiresAt, &invitation.AcceptedAt, &invitation.RevokedAt,
+		&invitation.CreatedAt)
+}
+
+// invitationTTL is configurable through INVITATION_TTL_HOURS (default 72).
+func invitationTTL() time.Duration {
+	return time.Duration(envInt("INVITATION_TTL_HOURS", 72)) * time.Hour
+}
+
+func CreateInvitationHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.ErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	claims, _ := auth.GetUserFromContext(r.Context())
+
+	var createReq models.CreateInvitationRequest
+	if err := json.NewDecoder(r.Body).Decode(&createReq); err != nil {
+		utils.ErrorResponse(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	createReq.Email = strings.TrimSpace(createReq.Email)
+	createReq.FullName = strings.TrimSpace(createReq.FullName)
+	if err := utils.ValidateEmail(createReq.Email); err != nil {
+		utils.ErrorResponse(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := utils.ValidateFullName(createReq.FullName); err != nil {
+		utils.ErrorResponse(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !auth.Role(createReq.Role).Valid() {
+		utils.ErrorResponse(w, "role must be one of admin, teacher, staff, read_only", http.StatusBadRequest)
+		return
+	}
+
+	// Soft-deleted users still hold their email, so they block invitations too.
+	var exists bool
+	err := database.DB.QueryRow(`
+		SELECT EXISTS(SELECT 1 FROM users WHERE email = $1)
+	`, createReq.Email).Scan(&exists)
+	if err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	if exists {
+		utils.ErrorResponse(w, "A user with this email already exists", http.StatusConflict)
+		return
+	}
+
+	token, tokenHash, err := auth.GenerateOpaqueToken()
+	if err != nil {
+		utils.ErrorResponse(w, "Failed to create invitation", http.StatusInternalServerError)
+		return
+	}
+
+	tx, err := database.DB.Begin()
+	if err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	// Only the most recent invitation for an address stays usable.
+	now := time.Now()
+	if _, err := tx.Exec(`
+		UPDATE user_invitations
+		SET revoked_at = $2
+		WHERE email = $1 AND accepted_at IS NULL AND revoked_at IS NULL
+	`, createReq.Email, now); err != nil {
+		utils.ErrorResponse(w, "Failed to create invitation", http.StatusInternalServerError)
+		return
+	}
+
+	var invitation models.Invitation
+	err = scanInvitation(tx.QueryRow(`
+		INSERT INTO user_invitations (email, full_name, role, token_hash, invited_by, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING `+invitationColumns,
+		createReq.Email, nullableString(createReq.FullName), createReq.Role, tokenHash,
+		actorID(claims), now.Add(invitationTTL())), &invitation)
+	if err != nil {
+		log.Printf("CreateInvitationHandler: error=%v", err)
+		utils.ErrorResponse(w, "Failed to create invitation", http.StatusInternalServerError)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	invitation.Token = token
+	utils.SuccessResponse(w, invitation, http.StatusCreated)
+}
+
+func GetInvitationsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.ErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	rows, err := database.DB.Query(`
+		SELECT ` + invitationColumns + `
+		FROM user_invitations
+		WHERE accepted_at IS NULL AND revoked_at IS NULL AND expires_at > NOW()
+		ORDER BY id
+	`)
+	if err != nil {
+		log.Printf("GetInvitationsHandler: query error: %v", err)
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	invitations := []models.Invitation{}
+	for rows.Next() {
+		var invitation models.Invitation
+		if err := scanInvitation(rows, &invitation); err != nil {
+			utils.ErrorResponse(w, "Error scanning results", http.StatusInternalServerError)
+			return
+		}
+		invitations = append(invitations, invitation)
+	}
+
+	if err = rows.Err(); err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	utils.SuccessResponse(w, models.InvitationsResponse{Invitations: invitations, Count: len(invitations)}, http.StatusOK)
+}
+
+func RevokeInvitationHandler(w http.ResponseWriter, r *http.Request) {
+	invitationID, err := parseIDParam(r, "id")
+	if err != nil {
+		utils.ErrorResponse(w, "Invalid invitation ID", http.StatusBadRequest)
+		return
+	}
+
+	result, err := database.DB.Exec(`
+		UPDATE user_invitations
+		SET revoked_at = $2
+		WHERE id = $1 AND accepted_at IS NULL AND revoked_at IS NULL
+	`, invitationID, time.Now())
+	if err != nil {
+		utils.ErrorResponse(w, "Failed to revoke invitation", http.StatusInternalServerError)
+		return
+	}
+
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		utils.ErrorResponse(w, "Invitation not found", http.StatusNotFound)
+		return
+	}
+
+	utils.SuccessResponse(w, nil, http.StatusNoContent)
+}
+
+func AcceptInvitationHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.ErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var acceptReq models.AcceptInvitationRequest
+	if err := json.NewDecoder(r.Body).Decode(&acceptReq); err != nil || acceptReq.Token == "" {
+		utils.ErrorResponse(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	acceptReq.FullName = strings.TrimSpace(acceptReq.FullName)
+	if err := utils.ValidatePassword(acceptReq.Password); err != nil {
+		utils.ErrorResponse(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := utils.ValidateFullName(acceptReq.FullName); err != nil {
+		utils.ErrorResponse(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	passwordHash, err := auth.HashPassword(acceptReq.Password)
+	if err != nil {
+		utils.ErrorResponse(w, "Failed to accept invitation", http.StatusInternalServerError)
+		return
+	}
+
+	tx, err := database.DB.Begin()
+	if err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	var invitation models.Invitation
+	err = scanInvitation(tx.QueryRow(`
+		SELECT `+invitationColumns+`
+		FROM user_invitations
+		WHERE token_hash = $1 AND accepted_at IS NULL AND revoked_at IS NULL AND expires_at > NOW()
+		FOR UPDATE
+	`, auth.HashToken(acceptReq.Token)), &invitation)
+
+	if err == sql.ErrNoRows {
+		utils.ErrorResponse(w, "Invitation is invalid or has expired", http.StatusBadRequest)
+		return
+	} else if err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	fullName := acceptReq.FullName
+	if fullName == "" {
+		fullName = stringValue(invitation.FullName)
+	}
+
+	var user models.User
+	now := time.Now()
+	err = tx.QueryRow(`
+		INSERT INTO users (email, full_name, password_hash, role, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, email, role
+	`, invitation.Email, nullableString(fullName), passwordHash, invitation.Role, now, now).Scan(
+		&user.ID, &user.Email, &user.Role)
+
+	if isUniqueViolation(err) {
+		utils.ErrorResponse(w, "A user with this email already exists", http.StatusConflict)
+		return
+	} else if err != nil {
+		log.Printf("AcceptInvitationHandler: error=%v", err)
+		utils.ErrorResponse(w, "Failed to accept invitation", http.StatusInternalServerError)
+		return
+	}
+
+	if _, err := tx.Exec(`
+		UPDATE user_invitations SET accepted_at = $2 WHERE id = $1
+	`, invitation.ID, now); err != nil {
+		utils.ErrorResponse(w, "Failed to accept invitation", http.StatusInternalServerError)
+		return
+	}
+
+	familyID, err := auth.NewTokenFamily()
+	if err != nil {
+		utils.ErrorResponse(w, "Failed to generate token", http.StatusInternalServerError)
+		return
+	}
+
+	response, err := issueTokens(tx, user, familyID)
+	if err != nil {
+		log.Printf("AcceptInvitationHandler: error=%v", err)
+		utils.ErrorResponse(w, "Failed to generate token", http.StatusInternalServerError)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	utils.SuccessResponse(w, response, http.StatusCreated)
+}
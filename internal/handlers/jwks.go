@@ -0,0 +1,27 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/Sea-Chels/go-practice-1/internal/auth"
+	"github.com/Sea-Chels/go-practice-1/internal/utils"
+)
+
+// JWKSHandler publishes the public keys used to verify access tokens.
+func JWKSHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.ErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	jwks, err := auth.PublicJWKS()
+	if err != nil {
+		log.Printf("JWKSHandler: error=%v", err)
+		utils.ErrorResponse(w, "Signing keys unavailable", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	utils.SuccessResponse(w, jwks, http.StatusOK)
+}
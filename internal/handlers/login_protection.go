@@ -0,0 +1,275 @@
+package handlers
+
+import (
+	"database/sql"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Sea-Chels/go-practice-1/internal/audit"
+	"github.com/Sea-Chels/go-practice-1/internal/auth"
+	"github.com/Sea-Chels/go-practice-1/internal/database"
+	"github.com/Sea-Chels/go-practice-1/internal/utils"
+)
+
+type loginThrottleConfig struct {
+	maxFailures      int
+	maxFailuresPerIP int
+	window           time.Duration
+	lockout          time.Duration
+	baseDelay        time.Duration
+	maxDelay         time.Duration
+}
+
+func loadLoginThrottleConfig() loginThrottleConfig {
+	return loginThrottleConfig{
+		maxFailures:      envInt("LOGIN_MAX_FAILED_ATTEMPTS", 5),
+		maxFailuresPerIP: envInt("LOGIN_MAX_FAILED_ATTEMPTS_PER_IP", 50),
+		window:           time.Duration(envInt("LOGIN_FAILURE_WINDOW_MINUTES", 15)) * time.Minute,
+		lockout:          time.Duration(envInt("LOGIN_LOCKOUT_MINUTES", 15)) * time.Minute,
+		baseDelay:        time.Second,
+		maxDelay:         30 * time.Second,
+	}
+}
+
+// delayAfter returns how long a client must wait after its nth consecutive
+// failure: 1s, 2s, 4s, ... capped at maxDelay.
+func (c loginThrottleConfig) delayAfter(failures int) time.Duration {
+	if failures <= 0 {
+		return 0
+	}
+	delay := time.Duration(float64(c.baseDelay) * math.Pow(2, float64(failures-1)))
+	if delay > c.maxDelay {
+		return c.maxDelay
+	}
+	return delay
+}
+
+// loginBlockedError is returned when an attempt must be rejected before the
+// password is even checked.
+type loginBlockedError struct {
+	message    string
+	retryAfter time.Duration
+}
+
+func (e *loginBlockedError) Error() string {
+	return e.message
+}
+
+// recentFailures counts failed attempts for email since its last reset that
+// fall inside the failure window, along with the time of the latest one.
+func recentFailures(db dbExecutor, cfg loginThrottleConfig, email string, now time.Time) (int, time.Time, error) {
+	var count int
+	var last sql.NullTime
+	err := db.QueryRow(`
+		SELECT COUNT(*), MAX(a.attempted_at)
+		FROM login_attempts a
+		LEFT JOIN login_throttles t ON t.email = a.email
+		WHERE a.email = $1 AND NOT a.succeeded
+		  AND a.attempted_at > $2
+		  AND (t.reset_at IS NULL OR a.attempted_at > t.reset_at)
+	`, email, now.Add(-cfg.window)).Scan(&count, &last)
+	return count, last.Time, err
+}
+
+// beginLoginAttempt opens a transaction holding the throttle row for email
+// and checks that the attempt is allowed. Concurrent attempts against one
+// account therefore wait for each other, so a burst cannot slip past the
+// failure limit before the lock is written. The caller records the outcome
+// on the returned transaction and commits it.
+func beginLoginAttempt(cfg loginThrottleConfig, email, ip string) (*sql.Tx, error) {
+	tx, err := database.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+
+	// The epoch reset_at keeps failures recorded before the row existed.
+	if _, err := tx.Exec(`
+		INSERT INTO login_throttles (email, locked_until, reset_at)
+		VALUES ($1, NULL, 'epoch')
+		ON CONFLICT (email) DO NOTHING
+	`, email); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := checkLoginAllowed(tx, cfg, email, ip); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	return tx, nil
+}
+
+// checkLoginAllowed enforces lockouts, progressive delays and the per-IP cap.
+// It locks the throttle row for email until db is committed.
+func checkLoginAllowed(db dbExecutor, cfg loginThrottleConfig, email, ip string) error {
+	now := time.Now()
+
+	var lockedUntil sql.NullTime
+	err := db.QueryRow(`
+		SELECT locked_until FROM login_throttles WHERE email = $1 FOR UPDATE
+	`, email).Scan(&lockedUntil)
+	if err != nil && err != sql.ErrNoRows {
+		return err
+	}
+	if lockedUntil.Valid && lockedUntil.Time.After(now) {
+		return &loginBlockedError{"Account temporarily locked due to too many failed login attempts", lockedUntil.Time.Sub(now)}
+	}
+
+	failures, lastFailure, err := recentFailures(db, cfg, email, now)
+	if err != nil {
+		return err
+	}
+	if wait := lastFailure.Add(cfg.delayAfter(failures)).Sub(now); failures > 0 && wait > 0 {
+		return &loginBlockedError{"Too many failed login attempts, try again later", wait}
+	}
+
+	var ipFailures int
+	var oldest sql.NullTime
+	err = db.QueryRow(`
+		SELECT COUNT(*), MIN(attempted_at)
+		FROM login_attempts
+		WHERE ip_address = $1 AND NOT succeeded AND attempted_at > $2
+	`, ip, now.Add(-cfg.window)).Scan(&ipFailures, &oldest)
+	if err != nil {
+		return err
+	}
+	if ipFailures >= cfg.maxFailuresPerIP {
+		return &loginBlockedError{"Too many failed login attempts, try again later", oldest.Time.Add(cfg.window).Sub(now)}
+	}
+
+	return nil
+}
+
+// recordLoginFailure stores a failed attempt and locks the account once the
+// threshold is reached.
+func recordLoginFailure(db dbExecutor, cfg loginThrottleConfig, email, ip string) error {
+	if _, err := db.Exec(`
+		INSERT INTO login_attempts (email, ip_address, succeeded) VALUES ($1, $2, false)
+	`, email, ip); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	failures, _, err := recentFailures(db, cfg, email, now)
+	if err != nil || failures < cfg.maxFailures {
+		return err
+	}
+
+	// Resetting the counter together with the lock gives the user a clean
+	// slate once the lockout expires.
+	lockedUntil := now.Add(cfg.lockout)
+	if _, err := db.Exec(`
+		INSERT INTO login_throttles (email, locked_until, reset_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (email) DO UPDATE SET locked_until = $2, reset_at = $3
+	`, email, lockedUntil, now); err != nil {
+		return err
+	}
+
+	log.Printf("Login: account %s locked until %s after %d failed attempts", email, lockedUntil.Format(time.RFC3339), failures)
+	return audit.Record(db, audit.Entry{
+		Action:     audit.ActionAccountLocked,
+		EntityType: "account",
+		EntityID:   email,
+		Details: map[string]interface{}{
+			"failed_attempts": failures,
+			"locked_until":    lockedUntil,
+		},
+		IPAddress: ip,
+	})
+}
+
+// recordLoginSuccess stores a successful attempt and resets the failure count.
+// It is only called once tokens have been issued, so a correct password
+// awaiting its second factor does not count.
+func recordLoginSuccess(db dbExecutor, email, ip string) error {
+	if _, err := db.Exec(`
+		INSERT INTO login_attempts (email, ip_address, succeeded) VALUES ($1, $2, true)
+	`, email, ip); err != nil {
+		return err
+	}
+
+	_, err := db.Exec(`
+		INSERT INTO login_throttles (email, locked_until, reset_at)
+		VALUES ($1, NULL, $2)
+		ON CONFLICT (email) DO UPDATE SET locked_until = NULL, reset_at = $2
+	`, email, time.Now())
+	return err
+}
+
+func writeLoginBlocked(w http.ResponseWriter, blocked *loginBlockedError) {
+	seconds := int(math.Ceil(blocked.retryAfter.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	w.Header().Set("Retry-After", strconv.Itoa(seconds))
+	utils.ErrorResponse(w, blocked.message, http.StatusTooManyRequests)
+}
+
+func UnlockUserHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.ErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID, err := parseIDParam(r, "id")
+	if err != nil {
+		utils.ErrorResponse(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	claims, _ := auth.GetUserFromContext(r.Context())
+
+	var email string
+	err = database.DB.QueryRow(`
+		SELECT email FROM users WHERE id = $1 AND deleted_at IS NULL
+	`, userID).Scan(&email)
+
+	if err == sql.ErrNoRows {
+		utils.ErrorResponse(w, "User not found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	tx, err := database.DB.Begin()
+	if err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`
+		INSERT INTO login_throttles (email, locked_until, reset_at)
+		VALUES ($1, NULL, $2)
+		ON CONFLICT (email) DO UPDATE SET locked_until = NULL, reset_at = $2
+	`, email, time.Now()); err != nil {
+		utils.ErrorResponse(w, "Failed to unlock user", http.StatusInternalServerError)
+		return
+	}
+
+	if err := audit.Record(tx, audit.Entry{
+		ActorID:    actorID(claims),
+		Action:     audit.ActionAccountUnlocked,
+		EntityType: "account",
+		EntityID:   email,
+		Details:    map[string]interface{}{"user_id": userID},
+		IPAddress:  utils.ClientIP(r),
+	}); err != nil {
+		log.Printf("UnlockUserHandler: error=%v", err)
+		utils.ErrorResponse(w, "Failed to unlock user", http.StatusInternalServerError)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	utils.SuccessResponse(w, nil, http.StatusNoContent)
+}
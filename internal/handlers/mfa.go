@@ -0,0 +1,464 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Sea-Chels/go-practice-1/internal/auth"
+	"github.com/Sea-Chels/go-practice-1/internal/database"
+	"github.com/Sea-Chels/go-practice-1/internal/models"
+	"github.com/Sea-Chels/go-practice-1/internal/utils"
+)
+
+const recoveryCodeCount = 10
+
+// maxMFAAttempts is how many wrong codes one MFA challenge token accepts
+// before it is invalidated and the user has to sign in again.
+const maxMFAAttempts = 5
+
+// mfaIssuer is the name shown in authenticator apps, configurable through MFA_ISSUER.
+func mfaIssuer() string {
+	if issuer := os.Getenv("MFA_ISSUER"); issuer != "" {
+		return issuer
+	}
+	return "School API"
+}
+
+// consumeTOTP validates code and records its time step so the same code
+// cannot be used twice.
+func consumeTOTP(db dbExecutor, userID int, secret, code string) (bool, error) {
+	step, ok := auth.ValidateTOTP(secret, code, time.Now())
+	if !ok {
+		return false, nil
+	}
+
+	result, err := db.Exec(`
+		UPDATE users
+		SET totp_last_step = $2
+		WHERE id = $1 AND (totp_last_step IS NULL OR totp_last_step < $2)
+	`, userID, step)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	return affected == 1, err
+}
+
+// consumeRecoveryCode marks a matching unused recovery code as used.
+func consumeRecoveryCode(db dbExecutor, userID int, code string) (bool, error) {
+	result, err := db.Exec(`
+		UPDATE mfa_recovery_codes
+		SET used_at = $3
+		WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL
+	`, userID, auth.HashToken(auth.NormalizeRecoveryCode(code)), time.Now())
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	return affected == 1, err
+}
+
+// replaceRecoveryCodes discards the user's recovery codes and stores a fresh set.
+func replaceRecoveryCodes(db dbExecutor, userID int) ([]string, error) {
+	codes, err := auth.GenerateRecoveryCodes(recoveryCodeCount)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := db.Exec(`DELETE FROM mfa_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return nil, err
+	}
+
+	for _, code := range codes {
+		if _, err := db.Exec(`
+			INSERT INTO mfa_recovery_codes (user_id, code_hash) VALUES ($1, $2)
+		`, userID, auth.HashToken(auth.NormalizeRecoveryCode(code))); err != nil {
+			return nil, err
+		}
+	}
+
+	return codes, nil
+}
+
+func VerifyMFAHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.ErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var verifyReq models.MFAVerifyRequest
+	if err := json.NewDecoder(r.Body).Decode(&verifyReq); err != nil {
+		utils.ErrorResponse(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if verifyReq.Code == "" && verifyReq.RecoveryCode == "" {
+		utils.ErrorResponse(w, "code or recovery_code is required", http.StatusBadRequest)
+		return
+	}
+
+	claims, err := auth.ValidateMFAToken(verifyReq.MFAToken)
+	if err != nil {
+		utils.ErrorResponse(w, "Invalid or expired MFA token", http.StatusUnauthorized)
+		return
+	}
+
+	// Wrong codes count towards the same lockout as wrong passwords.
+	throttle := loadLoginThrottleConfig()
+	throttleKey := strings.ToLower(claims.Email)
+	clientIP := utils.ClientIP(r)
+	tx, err := beginLoginAttempt(throttle, throttleKey, clientIP)
+	if err != nil {
+		if blocked, ok := err.(*loginBlockedError); ok {
+			writeLoginBlocked(w, blocked)
+			return
+		}
+		log.Printf("VerifyMFAHandler: throttle check failed: %v", err)
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	tokenHash := auth.HashToken(verifyReq.MFAToken)
+	usable, err := lockMFAChallenge(tx, tokenHash, claims)
+	if err != nil {
+		log.Printf("VerifyMFAHandler: error=%v", err)
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	if !usable {
+		utils.ErrorResponse(w, "Invalid or expired MFA token", http.StatusUnauthorized)
+		return
+	}
+
+	var user models.User
+	var secret string
+	err = tx.QueryRow(`
+		SELECT id, email, role, totp_secret
+		FROM users
+		WHERE id = $1 AND deleted_at IS NULL AND disabled_at IS NULL AND totp_enabled_at IS NOT NULL
+	`, claims.UserID).Scan(&user.ID, &user.Email, &user.Role, &secret)
+
+	if err == sql.ErrNoRows {
+		utils.ErrorResponse(w, "Invalid or expired MFA token", http.StatusUnauthorized)
+		return
+	} else if err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	var valid bool
+	if verifyReq.Code != "" {
+		valid, err = consumeTOTP(tx, user.ID, secret, verifyReq.Code)
+	} else {
+		valid, err = consumeRecoveryCode(tx, user.ID, verifyReq.RecoveryCode)
+	}
+	if err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	if !valid {
+		if _, err := tx.Exec(`
+			UPDATE mfa_challenges SET failed_attempts = failed_attempts + 1 WHERE token_hash = $1
+		`, tokenHash); err != nil {
+			log.Printf("VerifyMFAHandler: failed to record MFA failure: %v", err)
+		} else if err := recordLoginFailure(tx, throttle, throttleKey, clientIP); err != nil {
+			log.Printf("VerifyMFAHandler: failed to record MFA failure: %v", err)
+		} else if err := tx.Commit(); err != nil {
+			log.Printf("VerifyMFAHandler: failed to record MFA failure: %v", err)
+		}
+		utils.ErrorResponse(w, "Invalid verification code", http.StatusUnauthorized)
+		return
+	}
+
+	familyID, err := auth.NewTokenFamily()
+	if err != nil {
+		utils.ErrorResponse(w, "Failed to generate token", http.StatusInternalServerError)
+		return
+	}
+
+	response, err := issueTokens(tx, user, familyID)
+	if err != nil {
+		log.Printf("VerifyMFAHandler: error=%v", err)
+		utils.ErrorResponse(w, "Failed to generate token", http.StatusInternalServerError)
+		return
+	}
+
+	if _, err := tx.Exec(`
+		UPDATE mfa_challenges SET used_at = $2 WHERE token_hash = $1
+	`, tokenHash, time.Now()); err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	// The login only counts as successful once the second factor is in.
+	if err := recordLoginSuccess(tx, throttleKey, clientIP); err != nil {
+		log.Printf("VerifyMFAHandler: error=%v", err)
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	utils.SuccessResponse(w, response, http.StatusOK)
+}
+
+// lockMFAChallenge locks the tracking row for an MFA challenge token,
+// creating it on first use, and reports whether the token may still be
+// redeemed: it must not have been used and must have fewer than
+// maxMFAAttempts wrong codes against it.
+func lockMFAChallenge(db dbExecutor, tokenHash string, claims *auth.Claims) (bool, error) {
+	now := time.Now()
+	if _, err := db.Exec(`
+		DELETE FROM mfa_challenges WHERE user_id = $1 AND expires_at < $2
+	`, claims.UserID, now); err != nil {
+		return false, err
+	}
+
+	if _, err := db.Exec(`
+		INSERT INTO mfa_challenges (token_hash, user_id, expires_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (token_hash) DO NOTHING
+	`, tokenHash, claims.UserID, claims.ExpiresAt.Time); err != nil {
+		return false, err
+	}
+
+	var failedAttempts int
+	var used bool
+	err := db.QueryRow(`
+		SELECT failed_attempts, used_at IS NOT NULL
+		FROM mfa_challenges
+		WHERE token_hash = $1
+		FOR UPDATE
+	`, tokenHash).Scan(&failedAttempts, &used)
+	if err != nil {
+		return false, err
+	}
+
+	return !used && failedAttempts < maxMFAAttempts, nil
+}
+
+func EnrollMFAHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.ErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	claims, _ := auth.GetUserFromContext(r.Context())
+
+	secret, err := auth.GenerateTOTPSecret()
+	if err != nil {
+		utils.ErrorResponse(w, "Failed to start enrollment", http.StatusInternalServerError)
+		return
+	}
+
+	// Re-enrolling before confirming simply replaces the pending secret.
+	var email string
+	err = database.DB.QueryRow(`
+		UPDATE users
+		SET totp_secret = $2, totp_last_step = NULL, updated_at = $3
+		WHERE id = $1 AND deleted_at IS NULL AND totp_enabled_at IS NULL
+		RETURNING email
+	`, claims.UserID, secret, time.Now()).Scan(&email)
+
+	if err == sql.ErrNoRows {
+		utils.ErrorResponse(w, "Two-factor authentication is already enabled", http.StatusConflict)
+		return
+	} else if err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	response := models.MFAEnrollResponse{
+		Secret:          secret,
+		ProvisioningURI: auth.TOTPProvisioningURI(secret, email, mfaIssuer()),
+	}
+
+	utils.SuccessResponse(w, response, http.StatusOK)
+}
+
+func ConfirmMFAHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.ErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	claims, _ := auth.GetUserFromContext(r.Context())
+
+	var confirmReq models.MFACodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&confirmReq); err != nil {
+		utils.ErrorResponse(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	tx, err := database.DB.Begin()
+	if err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	var secret sql.NullString
+	err = tx.QueryRow(`
+		SELECT totp_secret FROM users
+		WHERE id = $1 AND deleted_at IS NULL AND totp_enabled_at IS NULL
+		FOR UPDATE
+	`, claims.UserID).Scan(&secret)
+
+	if err == sql.ErrNoRows {
+		utils.ErrorResponse(w, "Two-factor authentication is already enabled", http.StatusConflict)
+		return
+	} else if err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	if !secret.Valid {
+		utils.ErrorResponse(w, "Start enrollment before confirming", http.StatusBadRequest)
+		return
+	}
+
+	valid, err := consumeTOTP(tx, claims.UserID, secret.String, confirmReq.Code)
+	if err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	if !valid {
+		utils.ErrorResponse(w, "Invalid verification code", http.StatusBadRequest)
+		return
+	}
+
+	now := time.Now()
+	if _, err := tx.Exec(`
+		UPDATE users SET totp_enabled_at = $2, updated_at = $2 WHERE id = $1
+	`, claims.UserID, now); err != nil {
+		utils.ErrorResponse(w, "Failed to enable two-factor authentication", http.StatusInternalServerError)
+		return
+	}
+
+	codes, err := replaceRecoveryCodes(tx, claims.UserID)
+	if err != nil {
+		log.Printf("ConfirmMFAHandler: error=%v", err)
+		utils.ErrorResponse(w, "Failed to enable two-factor authentication", http.StatusInternalServerError)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	utils.SuccessResponse(w, models.MFARecoveryCodesResponse{RecoveryCodes: codes}, http.StatusOK)
+}
+
+func DisableMFAHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.ErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	claims, _ := auth.GetUserFromContext(r.Context())
+
+	var disableReq models.MFADisableRequest
+	if err := json.NewDecoder(r.Body).Decode(&disableReq); err != nil {
+		utils.ErrorResponse(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	var passwordHash, secret string
+	err := database.DB.QueryRow(`
+		SELECT password_hash, totp_secret FROM users
+		WHERE id = $1 AND deleted_at IS NULL AND totp_enabled_at IS NOT NULL
+	`, claims.UserID).Scan(&passwordHash, &secret)
+
+	if err == sql.ErrNoRows {
+		utils.ErrorResponse(w, "Two-factor authentication is not enabled", http.StatusBadRequest)
+		return
+	} else if err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	if !auth.CheckPassword(passwordHash, disableReq.Password) {
+		utils.ErrorResponse(w, "Current password is incorrect", http.StatusUnauthorized)
+		return
+	}
+
+	valid, err := consumeTOTP(database.DB, claims.UserID, secret, disableReq.Code)
+	if err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	if !valid {
+		utils.ErrorResponse(w, "Invalid verification code", http.StatusUnauthorized)
+		return
+	}
+
+	if err := clearMFA(claims.UserID); err != nil {
+		utils.ErrorResponse(w, "Failed to disable two-factor authentication", http.StatusInternalServerError)
+		return
+	}
+
+	utils.SuccessResponse(w, nil, http.StatusNoContent)
+}
+
+// ResetUserMFAHandler lets an admin remove two-factor authentication from an
+// account whose owner lost both their device and their recovery codes.
+func ResetUserMFAHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.ErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID, err := parseIDParam(r, "id")
+	if err != nil {
+		utils.ErrorResponse(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := clearMFA(userID); err == sql.ErrNoRows {
+		utils.ErrorResponse(w, "User not found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		utils.ErrorResponse(w, "Failed to reset two-factor authentication", http.StatusInternalServerError)
+		return
+	}
+
+	utils.SuccessResponse(w, nil, http.StatusNoContent)
+}
+
+func clearMFA(userID int) error {
+	tx, err := database.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`
+		UPDATE users
+		SET totp_secret = NULL, totp_enabled_at = NULL, totp_last_step = NULL, updated_at = $2
+		WHERE id = $1 AND deleted_at IS NULL
+	`, userID, time.Now())
+	if err != nil {
+		return err
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	if _, err := tx.Exec(`DELETE FROM mfa_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,209 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Sea-Chels/go-practice-1/internal/auth"
+	"github.com/Sea-Chels/go-practice-1/internal/database"
+	"github.com/Sea-Chels/go-practice-1/internal/mailer"
+	"github.com/Sea-Chels/go-practice-1/internal/models"
+	"github.com/Sea-Chels/go-practice-1/internal/utils"
+)
+
+const forgotPasswordMessage = "If an account exists for that email, a password reset link has been sent"
+
+// passwordResetTTL is configurable through PASSWORD_RESET_TTL_MINUTES (default 60).
+func passwordResetTTL() time.Duration {
+	return time.Duration(envInt("PASSWORD_RESET_TTL_MINUTES", 60)) * time.Minute
+}
+
+// passwordResetURL builds the link sent to the user from APP_BASE_URL.
+func passwordResetURL(token string) string {
+	baseURL := os.Getenv("APP_BASE_URL")
+	if baseURL == "" {
+		baseURL = "http://localhost:3000"
+	}
+	return strings.TrimRight(baseURL, "/") + "/reset-password?token=" + url.QueryEscape(token)
+}
+
+func ForgotPasswordHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.ErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var forgotReq models.ForgotPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&forgotReq); err != nil {
+		utils.ErrorResponse(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	forgotReq.Email = strings.TrimSpace(forgotReq.Email)
+	if err := utils.ValidateEmail(forgotReq.Email); err != nil {
+		utils.ErrorResponse(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// The response is identical whether or not the account exists so the
+	// endpoint cannot be used to enumerate users.
+	accepted := utils.SuccessResponseBody{Message: forgotPasswordMessage}
+
+	var userID int
+	err := database.DB.QueryRow(`
+		SELECT id FROM users
+		WHERE email = $1 AND deleted_at IS NULL AND disabled_at IS NULL
+	`, forgotReq.Email).Scan(&userID)
+
+	if err == sql.ErrNoRows {
+		utils.SuccessResponse(w, accepted, http.StatusAccepted)
+		return
+	} else if err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	// Creating the token and sending mail happen after responding, so the
+	// response time does not reveal whether the account exists either.
+	go sendPasswordReset(userID, forgotReq.Email)
+
+	utils.SuccessResponse(w, accepted, http.StatusAccepted)
+}
+
+// sendPasswordReset issues a reset token for the user and mails the link.
+// Failures can only be logged, as the request has already been answered.
+func sendPasswordReset(userID int, email string) {
+	token, tokenHash, err := auth.GenerateOpaqueToken()
+	if err != nil {
+		log.Printf("sendPasswordReset: failed to create token for user %d: %v", userID, err)
+		return
+	}
+
+	tx, err := database.DB.Begin()
+	if err != nil {
+		log.Printf("sendPasswordReset: user %d: %v", userID, err)
+		return
+	}
+	defer tx.Rollback()
+
+	// Only the most recently requested link stays valid.
+	now := time.Now()
+	if _, err := tx.Exec(`
+		UPDATE password_reset_tokens
+		SET used_at = $2
+		WHERE user_id = $1 AND used_at IS NULL
+	`, userID, now); err != nil {
+		log.Printf("sendPasswordReset: user %d: %v", userID, err)
+		return
+	}
+
+	ttl := passwordResetTTL()
+	if _, err := tx.Exec(`
+		INSERT INTO password_reset_tokens (user_id, token_hash, expires_at)
+		VALUES ($1, $2, $3)
+	`, userID, tokenHash, now.Add(ttl)); err != nil {
+		log.Printf("sendPasswordReset: user %d: %v", userID, err)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("sendPasswordReset: user %d: %v", userID, err)
+		return
+	}
+
+	msg := mailer.Message{
+		To:      email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("We received a request to reset your password.\n\n"+
+			"Open the link below to choose a new one. It expires in %d minutes and can only be used once.\n\n%s\n\n"+
+			"If you did not request this, you can ignore this email.",
+			int(ttl.Minutes()), passwordResetURL(token)),
+	}
+	if err := mailer.Default.Send(msg); err != nil {
+		log.Printf("sendPasswordReset: failed to send reset email to user %d: %v", userID, err)
+	}
+}
+
+func ResetPasswordHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.ErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var resetReq models.ResetPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&resetReq); err != nil || resetReq.Token == "" {
+		utils.ErrorResponse(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := utils.ValidatePassword(resetReq.Password); err != nil {
+		utils.ErrorResponse(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	passwordHash, err := auth.HashPassword(resetReq.Password)
+	if err != nil {
+		utils.ErrorResponse(w, "Failed to reset password", http.StatusInternalServerError)
+		return
+	}
+
+	tx, err := database.DB.Begin()
+	if err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	var tokenID, userID int
+	err = tx.QueryRow(`
+		SELECT t.id, t.user_id
+		FROM password_reset_tokens t
+		JOIN users u ON u.id = t.user_id
+		WHERE t.token_hash = $1 AND t.used_at IS NULL AND t.expires_at > NOW()
+		  AND u.deleted_at IS NULL AND u.disabled_at IS NULL
+		FOR UPDATE OF t
+	`, auth.HashToken(resetReq.Token)).Scan(&tokenID, &userID)
+
+	if err == sql.ErrNoRows {
+		utils.ErrorResponse(w, "Reset token is invalid or has expired", http.StatusBadRequest)
+		return
+	} else if err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	now := time.Now()
+	if _, err := tx.Exec(`
+		UPDATE users SET password_hash = $2, updated_at = $3 WHERE id = $1
+	`, userID, passwordHash, now); err != nil {
+		utils.ErrorResponse(w, "Failed to reset password", http.StatusInternalServerError)
+		return
+	}
+
+	if _, err := tx.Exec(`
+		UPDATE password_reset_tokens SET used_at = $2 WHERE id = $1
+	`, tokenID, now); err != nil {
+		utils.ErrorResponse(w, "Failed to reset password", http.StatusInternalServerError)
+		return
+	}
+
+	// Whoever knew the old password may still hold a session.
+	if err := revokeUserTokens(tx, userID); err != nil {
+		utils.ErrorResponse(w, "Failed to reset password", http.StatusInternalServerError)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	utils.SuccessResponse(w, nil, http.StatusNoContent)
+}
@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/Sea-Chels/go-practice-1/internal/auth"
+	"github.com/Sea-Chels/go-practice-1/internal/database"
+	"github.com/Sea-Chels/go-practice-1/internal/models"
+	"github.com/Sea-Chels/go-practice-1/internal/students"
+	"github.com/Sea-Chels/go-practice-1/internal/utils"
+)
+
+// writePromotionError maps the errors of the students package to responses.
+func writePromotionError(w http.ResponseWriter, handler string, err error) {
+	switch {
+	case errors.Is(err, students.ErrInvalidPromotion):
+		utils.ErrorResponse(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, students.ErrRunNotFound):
+		utils.ErrorResponse(w, "Promotion run not found", http.StatusNotFound)
+	case errors.Is(err, students.ErrRecentPromotion), errors.Is(err, students.ErrRunNotRevertible):
+		utils.ErrorResponse(w, err.Error(), http.StatusConflict)
+	default:
+		log.Printf("%s: error=%v", handler, err)
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+	}
+}
+
+func decodePromotionRequest(w http.ResponseWriter, r *http.Request) (students.PromotionOptions, bool) {
+	var req models.PromotionRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			utils.ErrorResponse(w, "Invalid request body", http.StatusBadRequest)
+			return students.PromotionOptions{}, false
+		}
+	}
+
+	opts, err := students.ParsePromotionRequest(req)
+	if err != nil {
+		utils.ErrorResponse(w, err.Error(), http.StatusBadRequest)
+		return opts, false
+	}
+	return opts, true
+}
+
+// PreviewPromotionHandler shows what POST /promotions would do with the same
+// body, without changing anything.
+func PreviewPromotionHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.ErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	opts, ok := decodePromotionRequest(w, r)
+	if !ok {
+		return
+	}
+
+	preview, err := students.PreviewPromotion(database.DB, opts)
+	if err != nil {
+		writePromotionError(w, "PreviewPromotionHandler", err)
+		return
+	}
+
+	utils.SuccessResponse(w, preview, http.StatusOK)
+}
+
+// ExecutePromotionHandler promotes every active student to the next grade and
+// graduates grade 12, except for the students listed in hold_back.
+func ExecutePromotionHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.ErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	claims, _ := auth.GetUserFromContext(r.Context())
+
+	opts, ok := decodePromotionRequest(w, r)
+	if !ok {
+		return
+	}
+	opts.ActorID = actorID(claims)
+	opts.IPAddress = utils.ClientIP(r)
+
+	run, err := students.ExecutePromotion(database.DB, opts)
+	if err != nil {
+		writePromotionError(w, "ExecutePromotionHandler", err)
+		return
+	}
+
+	utils.SuccessResponse(w, run, http.StatusCreated)
+}
+
+func GetPromotionRunsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.ErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	runs, err := students.ListPromotionRuns(database.DB)
+	if err != nil {
+		writePromotionError(w, "GetPromotionRunsHandler", err)
+		return
+	}
+
+	utils.SuccessResponse(w, models.PromotionRunsResponse{Runs: runs, Count: len(runs)}, http.StatusOK)
+}
+
+// RevertPromotionHandler undoes the most recent promotion run.
+func RevertPromotionHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.ErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	claims, _ := auth.GetUserFromContext(r.Context())
+
+	id, err := parseIDParam(r, "id")
+	if err != nil {
+		utils.ErrorResponse(w, "Invalid promotion run ID", http.StatusBadRequest)
+		return
+	}
+
+	run, err := students.RevertPromotion(database.DB, id, actorID(claims), utils.ClientIP(r))
+	if err != nil {
+		writePromotionError(w, "RevertPromotionHandler", err)
+		return
+	}
+
+	utils.SuccessResponse(w, run, http.StatusOK)
+}
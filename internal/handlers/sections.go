@@ -0,0 +1,422 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Sea-Chels/go-practice-1/internal/database"
+	"github.com/Sea-Chels/go-practice-1/internal/models"
+	"github.com/Sea-Chels/go-practice-1/internal/utils"
+)
+
+// sectionSelect reads sections together with their course, teacher and the
+// number of students currently enrolled. Like the roster, the count leaves
+// out deleted students.
+const sectionSelect = `
+	SELECT s.id, s.course_id, c.code, c.name, s.teacher_id, t.name, s.section_number, s.term, s.term_id,
+	       s.room, s.capacity,
+	       (SELECT COUNT(*) FROM enrollments e JOIN students st ON st.id = e.student_id
+	        WHERE e.section_id = s.id AND e.status = 'enrolled' AND st.deleted_at IS NULL),
+	       s.grading_scale_id, s.meeting_days, to_char(s.start_time, 'HH24:MI'), to_char(s.end_time, 'HH24:MI'),
+	       s.created_at, s.updated_at, s.deleted_at
+	FROM sections s
+	JOIN courses c ON c.id = s.course_id
+	LEFT JOIN teachers t ON t.id = s.teacher_id`
+
+func scanSection(row interface{ Scan(...interface{}) error }, section *models.Section) error {
+	return row.Scan(&section.ID, &section.CourseID, &section.CourseCode, &section.CourseName,
+		&section.TeacherID, &section.TeacherName, &section.SectionNumber, &section.Term, &section.TermID,
+		&section.Room, &section.Capacity, &section.Enrolled, &section.GradingScaleID, &section.MeetingDays, &section.StartTime, &section.EndTime,
+		&section.CreatedAt, &section.UpdatedAt, &section.DeletedAt)
+}
+
+// getSection loads an active section.
+func getSection(db dbExecutor, sectionID int) (models.Section, error) {
+	var section models.Section
+	err := scanSection(db.QueryRow(sectionSelect+`
+		WHERE s.id = $1 AND s.deleted_at IS NULL
+	`, sectionID), &section)
+	return section, err
+}
+
+func validateSectionRequest(req *models.CreateSectionRequest) error {
+	req.SectionNumber = strings.TrimSpace(req.SectionNumber)
+	req.Term = strings.TrimSpace(req.Term)
+	req.Room = strings.TrimSpace(req.Room)
+	req.MeetingDays = strings.ToUpper(strings.TrimSpace(req.MeetingDays))
+
+	if req.CourseID <= 0 {
+		return fmt.Errorf("course_id is required")
+	}
+	if req.TeacherID != nil && *req.TeacherID <= 0 {
+		return fmt.Errorf("teacher_id must be a teacher ID")
+	}
+	if req.SectionNumber == "" {
+		return fmt.Errorf("section_number is required")
+	}
+	if err := utils.ValidateMaxLength("section_number", req.SectionNumber, 10); err != nil {
+		return err
+	}
+	if req.TermID != nil && *req.TermID <= 0 {
+		return fmt.Errorf("term_id must be a term ID")
+	}
+	if req.Term == "" && req.TermID == nil {
+		return fmt.Errorf("term or term_id is required")
+	}
+	if err := utils.ValidateMaxLength("term", req.Term, 50); err != nil {
+		return err
+	}
+	if err := utils.ValidateMaxLength("room", req.Room, 50); err != nil {
+		return err
+	}
+	if req.Capacity < 1 {
+		return fmt.Errorf("capacity must be at least 1")
+	}
+	if req.GradingScaleID != nil && *req.GradingScaleID <= 0 {
+		return fmt.Errorf("grading_scale_id must be a grading scale ID")
+	}
+	if err := utils.ValidateMeetingDays(req.MeetingDays); err != nil {
+		return err
+	}
+
+	start, err := utils.ParseTimeOfDay("start_time", req.StartTime)
+	if err != nil {
+		return err
+	}
+	end, err := utils.ParseTimeOfDay("end_time", req.EndTime)
+	if err != nil {
+		return err
+	}
+	if (start == nil) != (end == nil) {
+		return fmt.Errorf("start_time and end_time must be given together")
+	}
+	if start != nil && !end.After(*start) {
+		return fmt.Errorf("end_time must be after start_time")
+	}
+	return nil
+}
+
+// checkSectionReferences makes sure the course, teacher, term and grading
+// scale of a section exist, filling in the term label from term_id when it is empty. It
+// returns a client error message, or "" when they do.
+func checkSectionReferences(db dbExecutor, req *models.CreateSectionRequest) (string, error) {
+	if exists, err := activeRowExists(db, "courses", req.CourseID); err != nil || !exists {
+		return "course_id does not refer to an existing course", err
+	}
+	if req.TeacherID != nil {
+		if exists, err := activeRowExists(db, "teachers", *req.TeacherID); err != nil || !exists {
+			return "teacher_id does not refer to an existing teacher", err
+		}
+	}
+	if req.TermID != nil {
+		var label string
+		err := db.QueryRow(`
+			SELECT y.name || ' ' || t.name FROM terms t JOIN academic_years y ON y.id = t.academic_year_id
+			WHERE t.id = $1
+		`, *req.TermID).Scan(&label)
+		if err == sql.ErrNoRows {
+			return "term_id does not refer to an existing term", nil
+		} else if err != nil {
+			return "", err
+		}
+		if req.Term == "" {
+			req.Term = label
+		}
+	}
+	if req.GradingScaleID != nil {
+		var exists bool
+		if err := db.QueryRow(`SELECT EXISTS(SELECT 1 FROM grading_scales WHERE id = $1)`, *req.GradingScaleID).Scan(&exists); err != nil || !exists {
+			return "grading_scale_id does not refer to an existing grading scale", err
+		}
+	}
+	return "", nil
+}
+
+// GetSectionsHandler lists sections, optionally filtered by course_id,
+// teacher_id, term_id and term.
+func GetSectionsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.ErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+	conditions := []string{"s.deleted_at IS NULL"}
+	var args []interface{}
+	for _, param := range []string{"course_id", "teacher_id", "term_id"} {
+		value := query.Get(param)
+		if value == "" {
+			continue
+		}
+		id, err := strconv.Atoi(value)
+		if err != nil || id <= 0 {
+			utils.ErrorResponse(w, fmt.Sprintf("%s must be a positive integer", param), http.StatusBadRequest)
+			return
+		}
+		args = append(args, id)
+		conditions = append(conditions, fmt.Sprintf("s.%s = $%d", param, len(args)))
+	}
+	if term := strings.TrimSpace(query.Get("term")); term != "" {
+		args = append(args, term)
+		conditions = append(conditions, fmt.Sprintf("s.term = $%d", len(args)))
+	}
+
+	rows, err := database.DB.Query(sectionSelect+`
+		`+whereClause(conditions)+`
+		ORDER BY s.term, c.code, s.section_number, s.id
+	`, args...)
+	if err != nil {
+		log.Printf("GetSectionsHandler: query error: %v", err)
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	sections := []models.Section{}
+	for rows.Next() {
+		var section models.Section
+		if err := scanSection(rows, &section); err != nil {
+			utils.ErrorResponse(w, "Error scanning results", http.StatusInternalServerError)
+			return
+		}
+		sections = append(sections, section)
+	}
+
+	if err = rows.Err(); err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	utils.SuccessResponse(w, models.SectionsResponse{Sections: sections, Count: len(sections)}, http.StatusOK)
+}
+
+func GetSectionHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.ErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	sectionID, err := parseIDParam(r, "id")
+	if err != nil {
+		utils.ErrorResponse(w, "Invalid section ID", http.StatusBadRequest)
+		return
+	}
+
+	section, err := getSection(database.DB, sectionID)
+	if err == sql.ErrNoRows {
+		utils.ErrorResponse(w, "Section not found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	utils.SuccessResponse(w, section, http.StatusOK)
+}
+
+func CreateSectionHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.ErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var createReq models.CreateSectionRequest
+	if err := json.NewDecoder(r.Body).Decode(&createReq); err != nil {
+		utils.ErrorResponse(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := validateSectionRequest(&createReq); err != nil {
+		utils.ErrorResponse(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if message, err := checkSectionReferences(database.DB, &createReq); err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	} else if message != "" {
+		utils.ErrorResponse(w, message, http.StatusBadRequest)
+		return
+	}
+
+	var sectionID int
+	now := time.Now()
+	err := database.DB.QueryRow(`
+		INSERT INTO sections (course_id, teacher_id, section_number, term, term_id, room, capacity,
+		                      grading_scale_id, meeting_days, start_time, end_time, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10::time, $11::time, $12, $12)
+		RETURNING id
+	`, createReq.CourseID, createReq.TeacherID, createReq.SectionNumber, createReq.Term, createReq.TermID,
+		nullableString(createReq.Room), createReq.Capacity, createReq.GradingScaleID, nullableString(createReq.MeetingDays),
+		nullableString(createReq.StartTime), nullableString(createReq.EndTime), now).Scan(&sectionID)
+
+	if isUniqueViolation(err) {
+		utils.ErrorResponse(w, "This course already has a section with this number in the term", http.StatusConflict)
+		return
+	} else if err != nil {
+		log.Printf("CreateSectionHandler: error=%v", err)
+		utils.ErrorResponse(w, "Failed to create section", http.StatusInternalServerError)
+		return
+	}
+
+	section, err := getSection(database.DB, sectionID)
+	if err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	utils.SuccessResponse(w, section, http.StatusCreated)
+}
+
+// UpdateSectionHandler replaces a section. The capacity cannot be lowered
+// below the number of students already enrolled.
+func UpdateSectionHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		utils.ErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	sectionID, err := parseIDParam(r, "id")
+	if err != nil {
+		utils.ErrorResponse(w, "Invalid section ID", http.StatusBadRequest)
+		return
+	}
+
+	var updateReq models.CreateSectionRequest
+	if err := json.NewDecoder(r.Body).Decode(&updateReq); err != nil {
+		utils.ErrorResponse(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := validateSectionRequest(&updateReq); err != nil {
+		utils.ErrorResponse(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	tx, err := database.DB.Begin()
+	if err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	// Locking the section keeps enrollments from filling it meanwhile.
+	var current models.Section
+	var enrolled int
+	var hasEnrollments bool
+	err = tx.QueryRow(`
+		SELECT s.course_id, s.term, s.term_id,
+		       (SELECT COUNT(*) FROM enrollments e JOIN students st ON st.id = e.student_id
+		        WHERE e.section_id = s.id AND e.status = 'enrolled' AND st.deleted_at IS NULL),
+		       EXISTS (SELECT 1 FROM enrollments e WHERE e.section_id = s.id AND e.status = 'enrolled')
+		FROM sections s
+		WHERE s.id = $1 AND s.deleted_at IS NULL
+		FOR UPDATE
+	`, sectionID).Scan(&current.CourseID, &current.Term, &current.TermID, &enrolled, &hasEnrollments)
+	if err == sql.ErrNoRows {
+		utils.ErrorResponse(w, "Section not found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	if updateReq.Capacity < enrolled {
+		utils.ErrorResponse(w, fmt.Sprintf("capacity must be at least %d, the number of students enrolled", enrolled), http.StatusConflict)
+		return
+	}
+
+	// With the same term_id and no term in the request the section keeps its
+	// label, even if the term has been renamed since.
+	sameTermID := current.TermID != nil && updateReq.TermID != nil && *current.TermID == *updateReq.TermID
+	if sameTermID && updateReq.Term == "" {
+		updateReq.Term = current.Term
+	}
+
+	if message, err := checkSectionReferences(tx, &updateReq); err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	} else if message != "" {
+		utils.ErrorResponse(w, message, http.StatusBadRequest)
+		return
+	}
+
+	// Moving enrolled students to another course or term could put them in
+	// two sections of one course for the same term. Sections in the calendar
+	// are compared by term_id, so relabelling them is allowed.
+	termChanged := !sameTermID && (current.TermID != nil || updateReq.TermID != nil || updateReq.Term != current.Term)
+	if hasEnrollments && (updateReq.CourseID != current.CourseID || termChanged) {
+		utils.ErrorResponse(w, "The course and term cannot change while students are enrolled", http.StatusConflict)
+		return
+	}
+
+	_, err = tx.Exec(`
+		UPDATE sections
+		SET course_id = $2, teacher_id = $3, section_number = $4, term = $5, term_id = $6, room = $7,
+		    capacity = $8, grading_scale_id = $9, meeting_days = $10, start_time = $11::time,
+		    end_time = $12::time, updated_at = $13
+		WHERE id = $1
+	`, sectionID, updateReq.CourseID, updateReq.TeacherID, updateReq.SectionNumber, updateReq.Term, updateReq.TermID,
+		nullableString(updateReq.Room), updateReq.Capacity, updateReq.GradingScaleID, nullableString(updateReq.MeetingDays),
+		nullableString(updateReq.StartTime), nullableString(updateReq.EndTime), time.Now())
+
+	if isUniqueViolation(err) {
+		utils.ErrorResponse(w, "This course already has a section with this number in the term", http.StatusConflict)
+		return
+	} else if err != nil {
+		log.Printf("UpdateSectionHandler: error=%v", err)
+		utils.ErrorResponse(w, "Failed to update section", http.StatusInternalServerError)
+		return
+	}
+
+	section, err := getSection(tx, sectionID)
+	if err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	utils.SuccessResponse(w, section, http.StatusOK)
+}
+
+// DeleteSectionHandler soft deletes a section without enrolled students.
+func DeleteSectionHandler(w http.ResponseWriter, r *http.Request) {
+	sectionID, err := parseIDParam(r, "id")
+	if err != nil {
+		utils.ErrorResponse(w, "Invalid section ID", http.StatusBadRequest)
+		return
+	}
+
+	now := time.Now()
+	result, err := database.DB.Exec(`
+		UPDATE sections
+		SET deleted_at = $2, updated_at = $3
+		WHERE id = $1 AND deleted_at IS NULL
+		  AND NOT EXISTS (SELECT 1 FROM enrollments WHERE section_id = $1 AND status = 'enrolled')
+	`, sectionID, now, now)
+	if err != nil {
+		log.Printf("DeleteSectionHandler: error=%v", err)
+		utils.ErrorResponse(w, "Failed to delete section", http.StatusInternalServerError)
+		return
+	}
+
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		if exists, err := activeRowExists(database.DB, "sections", sectionID); err == nil && exists {
+			utils.ErrorResponse(w, "Section still has enrolled students; drop them first", http.StatusConflict)
+			return
+		}
+		utils.ErrorResponse(w, "Section not found", http.StatusNotFound)
+		return
+	}
+
+	utils.SuccessResponse(w, nil, http.StatusNoContent)
+}
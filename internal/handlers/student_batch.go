@@ -0,0 +1,220 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/Sea-Chels/go-practice-1/internal/audit"
+	"github.com/Sea-Chels/go-practice-1/internal/auth"
+	"github.com/Sea-Chels/go-practice-1/internal/database"
+	"github.com/Sea-Chels/go-practice-1/internal/models"
+	"github.com/Sea-Chels/go-practice-1/internal/utils"
+)
+
+// batchOpError is the outcome of an operation that did not succeed.
+type batchOpError struct {
+	status  int
+	message string
+}
+
+func (e *batchOpError) Error() string {
+	return e.message
+}
+
+// studentBatchLimit is configurable through STUDENT_BATCH_MAX_OPERATIONS
+// (default 100).
+func studentBatchLimit() int {
+	return envInt("STUDENT_BATCH_MAX_OPERATIONS", 100)
+}
+
+// BatchStudentsHandler runs a list of create, update and delete operations in
+// one transaction. In atomic mode the first failure rolls everything back;
+// otherwise each operation succeeds or fails on its own.
+func BatchStudentsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.ErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	claims, _ := auth.GetUserFromContext(r.Context())
+
+	var batchReq models.StudentBatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&batchReq); err != nil {
+		utils.ErrorResponse(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	limit := studentBatchLimit()
+	if len(batchReq.Operations) == 0 || len(batchReq.Operations) > limit {
+		utils.ErrorResponse(w, fmt.Sprintf("operations must contain between 1 and %d items", limit), http.StatusBadRequest)
+		return
+	}
+
+	counts := map[string]int{}
+	for i, op := range batchReq.Operations {
+		switch op.Op {
+		case "create", "update":
+		case "delete":
+			// The route only requires students:write.
+			if !claims.Can(auth.PermStudentsDelete) {
+				utils.ErrorResponse(w, "Insufficient permissions to delete students", http.StatusForbidden)
+				return
+			}
+		default:
+			utils.ErrorResponse(w, fmt.Sprintf("operations[%d].op must be one of create, update, delete", i), http.StatusBadRequest)
+			return
+		}
+		counts[op.Op]++
+	}
+
+	response := models.StudentBatchResponse{
+		Atomic:  batchReq.Atomic == nil || *batchReq.Atomic,
+		Results: []models.StudentBatchResult{},
+	}
+
+	tx, err := database.DB.Begin()
+	if err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	for i, op := range batchReq.Operations {
+		result := models.StudentBatchResult{Index: i, Op: op.Op, ID: op.ID}
+
+		// Savepoints let a failed operation be undone without aborting
+		// the others in partial mode.
+		if _, err := tx.Exec(`SAVEPOINT batch_op`); err != nil {
+			utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+			return
+		}
+
+		student, status, err := runStudentBatchOp(tx, op)
+		var opErr *batchOpError
+		if errors.As(err, &opErr) {
+			if _, err := tx.Exec(`ROLLBACK TO SAVEPOINT batch_op`); err != nil {
+				utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+				return
+			}
+			result.Status = opErr.status
+			result.Error = opErr.message
+			response.Failed++
+			response.Results = append(response.Results, result)
+			if response.Atomic {
+				break
+			}
+			continue
+		} else if err != nil {
+			log.Printf("BatchStudentsHandler: operation %d error=%v", i, err)
+			utils.ErrorResponse(w, "Failed to run batch", http.StatusInternalServerError)
+			return
+		}
+
+		if _, err := tx.Exec(`RELEASE SAVEPOINT batch_op`); err != nil {
+			utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+			return
+		}
+		result.Status = status
+		result.Student = student
+		if student != nil {
+			result.ID = student.ID
+		}
+		response.Succeeded++
+		response.Results = append(response.Results, result)
+	}
+
+	if response.Atomic && response.Failed > 0 {
+		utils.SuccessResponse(w, response, http.StatusUnprocessableEntity)
+		return
+	}
+
+	if err := audit.Record(tx, audit.Entry{
+		ActorID:    actorID(claims),
+		Action:     audit.ActionStudentsBatch,
+		EntityType: "student",
+		Details: map[string]interface{}{
+			"atomic":     response.Atomic,
+			"operations": counts,
+			"succeeded":  response.Succeeded,
+			"failed":     response.Failed,
+		},
+		IPAddress: utils.ClientIP(r),
+	}); err != nil {
+		log.Printf("BatchStudentsHandler: error=%v", err)
+		utils.ErrorResponse(w, "Failed to run batch", http.StatusInternalServerError)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	response.Committed = true
+	utils.SuccessResponse(w, response, http.StatusOK)
+}
+
+// runStudentBatchOp applies one operation. Failures the client can fix are
+// returned as *batchOpError; any other error aborts the whole batch.
+func runStudentBatchOp(tx *sql.Tx, op models.StudentBatchOperation) (*models.Student, int, error) {
+	if op.Op == "create" {
+		var createReq models.CreateStudentRequest
+		if err := decodeStrict(op.Data, &createReq); err != nil {
+			return nil, 0, &batchOpError{http.StatusBadRequest, "Invalid data: " + err.Error()}
+		}
+		if err := validateStudentRequest(&createReq); err != nil {
+			return nil, 0, &batchOpError{http.StatusBadRequest, err.Error()}
+		}
+		student, err := insertStudent(tx, createReq)
+		if isUniqueViolation(err) {
+			return nil, 0, &batchOpError{http.StatusConflict, errStudentNumberTaken}
+		} else if err != nil {
+			return nil, 0, err
+		}
+		return &student, http.StatusCreated, nil
+	}
+
+	if op.ID <= 0 {
+		return nil, 0, &batchOpError{http.StatusBadRequest, "id is required"}
+	}
+	if op.Version <= 0 {
+		return nil, 0, &batchOpError{http.StatusPreconditionRequired, "version is required"}
+	}
+
+	current, err := lockStudent(tx, op.ID)
+	if err == sql.ErrNoRows {
+		return nil, 0, &batchOpError{http.StatusNotFound, "Student not found"}
+	} else if err != nil {
+		return nil, 0, err
+	}
+	if current.Version != op.Version {
+		return nil, 0, &batchOpError{http.StatusPreconditionFailed,
+			fmt.Sprintf("Student was modified by someone else; current version is %d", current.Version)}
+	}
+
+	if op.Op == "delete" {
+		if err := softDeleteStudent(tx, op.ID); err != nil {
+			return nil, 0, err
+		}
+		return nil, http.StatusNoContent, nil
+	}
+
+	patchReq, err := patchStudentRequest(current, op.Data)
+	if err != nil {
+		return nil, 0, &batchOpError{http.StatusBadRequest, "Invalid merge patch: " + err.Error()}
+	}
+	if err := validateStudentRequest(&patchReq); err != nil {
+		return nil, 0, &batchOpError{http.StatusBadRequest, err.Error()}
+	}
+	student, err := updateStudent(tx, op.ID, patchReq)
+	if isUniqueViolation(err) {
+		return nil, 0, &batchOpError{http.StatusConflict, errStudentNumberTaken}
+	} else if err != nil {
+		return nil, 0, err
+	}
+	return &student, http.StatusOK, nil
+}
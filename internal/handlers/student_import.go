@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/Sea-Chels/go-practice-1/internal/auth"
+	"github.com/Sea-Chels/go-practice-1/internal/database"
+	"github.com/Sea-Chels/go-practice-1/internal/students"
+	"github.com/Sea-Chels/go-practice-1/internal/utils"
+)
+
+const maxImportFileSize = 10 << 20
+
+// ImportStudentsHandler imports a CSV roster, sent either as the raw request
+// body (text/csv) or as the "file" field of a multipart form. With
+// dry_run=true nothing is saved and the report shows what would happen.
+func ImportStudentsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.ErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	claims, _ := auth.GetUserFromContext(r.Context())
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportFileSize)
+
+	var file io.Reader = r.Body
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		upload, _, err := r.FormFile("file")
+		if err != nil {
+			utils.ErrorResponse(w, "Multipart upload must contain a CSV file in the \"file\" field", http.StatusBadRequest)
+			return
+		}
+		defer upload.Close()
+		file = upload
+	}
+
+	report, err := students.Import(database.DB, file, students.ImportOptions{
+		DryRun:    r.URL.Query().Get("dry_run") == "true",
+		ActorID:   actorID(claims),
+		IPAddress: utils.ClientIP(r),
+	})
+	if errors.Is(err, students.ErrInvalidFile) {
+		utils.ErrorResponse(w, err.Error(), http.StatusBadRequest)
+		return
+	} else if err != nil {
+		log.Printf("ImportStudentsHandler: error=%v", err)
+		utils.ErrorResponse(w, "Failed to import students", http.StatusInternalServerError)
+		return
+	}
+
+	utils.SuccessResponse(w, report, http.StatusOK)
+}
@@ -0,0 +1,254 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Sea-Chels/go-practice-1/internal/models"
+	"github.com/Sea-Chels/go-practice-1/internal/utils"
+)
+
+const (
+	defaultStudentPageSize = 50
+	maxStudentPageSize     = 200
+)
+
+// studentSortFields whitelists the columns students can be ordered by, along
+// with the SQL type cursor values are compared as.
+var studentSortFields = map[string]string{
+	"id":         "integer",
+	"name":       "text",
+	"last_name":  "text",
+	"grade":      "integer",
+	"created_at": "timestamptz",
+	"updated_at": "timestamptz",
+}
+
+// studentFilter holds the list filters shared by every endpoint that returns
+// a set of students.
+type studentFilter struct {
+	includeDeleted bool
+	grade          *int
+	gradeMin       *int
+	gradeMax       *int
+	createdAfter   *time.Time
+	namePrefix     string
+	status         string
+}
+
+func parseStudentFilter(query url.Values) (studentFilter, error) {
+	filter := studentFilter{
+		includeDeleted: query.Get("include_deleted") == "true",
+		namePrefix:     strings.TrimSpace(query.Get("name")),
+		status:         query.Get("status"),
+	}
+
+	if filter.status != "" {
+		if err := utils.ValidateStudentStatus(filter.status); err != nil {
+			return filter, err
+		}
+	}
+
+	var err error
+	if filter.grade, err = parseGradeParam(query, "grade"); err != nil {
+		return filter, err
+	}
+	if filter.gradeMin, err = parseGradeParam(query, "grade_min"); err != nil {
+		return filter, err
+	}
+	if filter.gradeMax, err = parseGradeParam(query, "grade_max"); err != nil {
+		return filter, err
+	}
+	if filter.gradeMin != nil && filter.gradeMax != nil && *filter.gradeMin > *filter.gradeMax {
+		return filter, fmt.Errorf("grade_min must not be greater than grade_max")
+	}
+
+	if value := query.Get("created_after"); value != "" {
+		createdAfter, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			date, dateErr := utils.ParseDate("created_after", value)
+			if dateErr != nil {
+				return filter, fmt.Errorf("created_after must be an RFC 3339 timestamp or a YYYY-MM-DD date")
+			}
+			createdAfter = *date
+		}
+		filter.createdAfter = &createdAfter
+	}
+
+	return filter, nil
+}
+
+func parseGradeParam(query url.Values, name string) (*int, error) {
+	value := query.Get(name)
+	if value == "" {
+		return nil, nil
+	}
+	grade, err := strconv.Atoi(value)
+	if err != nil || utils.ValidateGrade(grade) != nil {
+		return nil, fmt.Errorf("%s must be between 1 and 12", name)
+	}
+	return &grade, nil
+}
+
+// conditions appends the filter's WHERE clauses to conditions, adding their
+// parameters to args.
+func (f studentFilter) conditions(conditions []string, args []interface{}) ([]string, []interface{}) {
+	if !f.includeDeleted {
+		conditions = append(conditions, "deleted_at IS NULL")
+	}
+	if f.grade != nil {
+		args = append(args, *f.grade)
+		conditions = append(conditions, fmt.Sprintf("grade = $%d", len(args)))
+	}
+	if f.gradeMin != nil {
+		args = append(args, *f.gradeMin)
+		conditions = append(conditions, fmt.Sprintf("grade >= $%d", len(args)))
+	}
+	if f.gradeMax != nil {
+		args = append(args, *f.gradeMax)
+		conditions = append(conditions, fmt.Sprintf("grade <= $%d", len(args)))
+	}
+	if f.createdAfter != nil {
+		args = append(args, *f.createdAfter)
+		conditions = append(conditions, fmt.Sprintf("created_at > $%d", len(args)))
+	}
+	if f.namePrefix != "" {
+		args = append(args, escapeLike(f.namePrefix)+"%")
+		conditions = append(conditions, fmt.Sprintf("lower(name) LIKE lower($%d)", len(args)))
+	}
+	if f.status != "" {
+		args = append(args, f.status)
+		conditions = append(conditions, fmt.Sprintf("status = $%d", len(args)))
+	}
+	return conditions, args
+}
+
+// escapeLike escapes the LIKE wildcards in a user supplied value.
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(value)
+}
+
+func whereClause(conditions []string) string {
+	if len(conditions) == 0 {
+		return ""
+	}
+	return "WHERE " + strings.Join(conditions, " AND ")
+}
+
+// studentSort is a whitelisted sort field. A leading "-" in the sort
+// parameter selects descending order.
+type studentSort struct {
+	field string
+	desc  bool
+}
+
+func parseStudentSort(value string) (studentSort, error) {
+	sort := studentSort{field: "id"}
+	if value == "" {
+		return sort, nil
+	}
+
+	sort.desc = strings.HasPrefix(value, "-")
+	sort.field = strings.TrimPrefix(value, "-")
+	if _, ok := studentSortFields[sort.field]; !ok {
+		return sort, fmt.Errorf("sort must be one of id, name, last_name, grade, created_at, updated_at (prefix with - for descending)")
+	}
+	return sort, nil
+}
+
+func (s studentSort) String() string {
+	if s.desc {
+		return "-" + s.field
+	}
+	return s.field
+}
+
+// orderBy always breaks ties on id so the order is stable across pages.
+func (s studentSort) orderBy() string {
+	direction := "ASC"
+	if s.desc {
+		direction = "DESC"
+	}
+	if s.field == "id" {
+		return "id " + direction
+	}
+	return fmt.Sprintf("%s %s, id %s", s.field, direction, direction)
+}
+
+// studentCursor marks the last row of a page. It is handed to clients as an
+// opaque base64 string and only valid with the sort it was issued for.
+type studentCursor struct {
+	Sort  string `json:"s"`
+	Value string `json:"v"`
+	ID    int    `json:"id"`
+}
+
+func (s studentSort) cursorFor(student models.Student) string {
+	cursor := studentCursor{Sort: s.String(), ID: student.ID}
+	switch s.field {
+	case "id":
+		cursor.Value = strconv.Itoa(student.ID)
+	case "name":
+		cursor.Value = student.Name
+	case "last_name":
+		cursor.Value = student.LastName
+	case "grade":
+		cursor.Value = strconv.Itoa(student.Grade)
+	case "created_at":
+		cursor.Value = student.CreatedAt.Format(time.RFC3339Nano)
+	case "updated_at":
+		cursor.Value = student.UpdatedAt.Format(time.RFC3339Nano)
+	}
+
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func (s studentSort) parseCursor(value string) (*studentCursor, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	var cursor studentCursor
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil || json.Unmarshal(data, &cursor) != nil || cursor.ID <= 0 {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+	if cursor.Sort != s.String() {
+		return nil, fmt.Errorf("cursor was issued for sort=%s", cursor.Sort)
+	}
+	return &cursor, nil
+}
+
+// after appends the keyset condition selecting rows that follow the cursor.
+func (s studentSort) after(cursor *studentCursor, conditions []string, args []interface{}) ([]string, []interface{}) {
+	operator := ">"
+	if s.desc {
+		operator = "<"
+	}
+
+	if s.field == "id" {
+		args = append(args, cursor.ID)
+		return append(conditions, fmt.Sprintf("id %s $%d", operator, len(args))), args
+	}
+
+	args = append(args, cursor.Value, cursor.ID)
+	return append(conditions, fmt.Sprintf("(%s, id) %s ($%d::%s, $%d)",
+		s.field, operator, len(args)-1, studentSortFields[s.field], len(args))), args
+}
+
+func parsePageSize(value string) (int, error) {
+	if value == "" {
+		return defaultStudentPageSize, nil
+	}
+	limit, err := strconv.Atoi(value)
+	if err != nil || limit < 1 || limit > maxStudentPageSize {
+		return 0, fmt.Errorf("limit must be between 1 and %d", maxStudentPageSize)
+	}
+	return limit, nil
+}
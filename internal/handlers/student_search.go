@@ -0,0 +1,182 @@
+package handlers
+
+import (
+	"fmt"
+	"html"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/Sea-Chels/go-practice-1/internal/database"
+	"github.com/Sea-Chels/go-practice-1/internal/models"
+	"github.com/Sea-Chels/go-practice-1/internal/utils"
+)
+
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 100
+	maxSearchLength    = 100
+	// highlightSimilarity is compared word by word. Short words share few
+	// trigrams, so it sits below pg_trgm's 0.3 default for whole names.
+	highlightSimilarity = 0.25
+)
+
+// SearchStudentsHandler ranks students whose name matches q either as words
+// (full text, with prefix matching) or approximately (trigram similarity).
+// The list filters of GET /students apply as well.
+func SearchStudentsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.ErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+	q := strings.TrimSpace(query.Get("q"))
+	if q == "" || len(q) > maxSearchLength {
+		utils.ErrorResponse(w, fmt.Sprintf("q is required and must not exceed %d characters", maxSearchLength), http.StatusBadRequest)
+		return
+	}
+	terms := searchTerms(q)
+	if len(terms) == 0 {
+		utils.ErrorResponse(w, "q must contain at least one letter or digit", http.StatusBadRequest)
+		return
+	}
+
+	filter, err := parseStudentFilter(query)
+	if err != nil {
+		utils.ErrorResponse(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	limit := defaultSearchLimit
+	if value := query.Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > maxSearchLimit {
+			utils.ErrorResponse(w, fmt.Sprintf("limit must be between 1 and %d", maxSearchLimit), http.StatusBadRequest)
+			return
+		}
+		limit = parsed
+	}
+
+	// Terms only contain letters and digits, so they are safe to join into
+	// tsquery syntax; any matching word counts, more matches rank higher.
+	prefixes := make([]string, len(terms))
+	for i, term := range terms {
+		prefixes[i] = term + ":*"
+	}
+	args := []interface{}{q, strings.Join(prefixes, " | ")}
+	conditions, args := filter.conditions([]string{
+		"(to_tsvector('simple', name) @@ to_tsquery('simple', $2) OR name % $1)",
+	}, args)
+	args = append(args, limit)
+
+	rows, err := database.DB.Query(fmt.Sprintf(`
+		SELECT %s,
+		       ts_rank(to_tsvector('simple', name), to_tsquery('simple', $2)) + similarity(name, $1) AS score
+		FROM students
+		%s
+		ORDER BY score DESC, id
+		LIMIT $%d
+	`, studentColumns, whereClause(conditions), len(args)), args...)
+	if err != nil {
+		log.Printf("SearchStudentsHandler: query error: %v", err)
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	results := []models.StudentSearchResult{}
+	for rows.Next() {
+		var result models.StudentSearchResult
+		if err := scanStudent(rows, &result.Student, &result.Score); err != nil {
+			utils.ErrorResponse(w, "Error scanning results", http.StatusInternalServerError)
+			return
+		}
+		result.Highlight = highlightName(result.Name, terms)
+		results = append(results, result)
+	}
+
+	if err = rows.Err(); err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	utils.SuccessResponse(w, models.StudentSearchResponse{Query: q, Results: results, Count: len(results)}, http.StatusOK)
+}
+
+// searchTerms splits a query into lower-case words of letters and digits.
+func searchTerms(q string) []string {
+	return strings.FieldsFunc(strings.ToLower(q), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// highlightName wraps each word of name that starts with, or closely
+// resembles, one of the search terms in <mark> tags.
+func highlightName(name string, terms []string) string {
+	var b strings.Builder
+	word := []rune{}
+
+	flush := func() {
+		if len(word) == 0 {
+			return
+		}
+		text := string(word)
+		if wordMatches(strings.ToLower(text), terms) {
+			b.WriteString("<mark>" + html.EscapeString(text) + "</mark>")
+		} else {
+			b.WriteString(html.EscapeString(text))
+		}
+		word = word[:0]
+	}
+
+	for _, r := range name {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			word = append(word, r)
+			continue
+		}
+		flush()
+		b.WriteString(html.EscapeString(string(r)))
+	}
+	flush()
+
+	return b.String()
+}
+
+func wordMatches(word string, terms []string) bool {
+	for _, term := range terms {
+		if strings.HasPrefix(word, term) || trigramSimilarity(word, term) >= highlightSimilarity {
+			return true
+		}
+	}
+	return false
+}
+
+// trigramSimilarity mirrors pg_trgm's similarity() for a single word: the
+// share of distinct trigrams, with the word padded by two leading spaces and
+// one trailing space, that both words have in common.
+func trigramSimilarity(a, b string) float64 {
+	setA, setB := trigrams(a), trigrams(b)
+	shared := 0
+	for trigram := range setA {
+		if setB[trigram] {
+			shared++
+		}
+	}
+	union := len(setA) + len(setB) - shared
+	if union == 0 {
+		return 0
+	}
+	return float64(shared) / float64(union)
+}
+
+func trigrams(word string) map[string]bool {
+	runes := []rune("  " + word + " ")
+	set := map[string]bool{}
+	for i := 0; i+3 <= len(runes); i++ {
+		set[string(runes[i:i+3])] = true
+	}
+	return set
+}
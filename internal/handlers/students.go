@@ -1,67 +1,107 @@
 package handlers
 
 import (
+	"database/sql"
 	"encoding/json"
+	"fmt"
+	"io"
 	"log"
 	"net/http"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/Sea-Chels/go-practice-1/internal/audit"
+	"github.com/Sea-Chels/go-practice-1/internal/auth"
 	"github.com/Sea-Chels/go-practice-1/internal/database"
 	"github.com/Sea-Chels/go-practice-1/internal/models"
 	"github.com/Sea-Chels/go-practice-1/internal/utils"
-	"github.com/gorilla/mux"
 )
 
+const studentColumns = `id, name, first_name, last_name, preferred_name, grade, student_number, external_id,
+	date_of_birth, gender, address_line1, address_line2, city, state, postal_code, country,
+	enrollment_date, status, graduated_at, version, created_at, updated_at, deleted_at`
+
+// scanStudent scans studentColumns, followed by any extra columns the query
+// selects after them.
+func scanStudent(row interface{ Scan(...interface{}) error }, student *models.Student, extra ...interface{}) error {
+	address := &student.Address
+	return row.Scan(append([]interface{}{
+		&student.ID, &student.Name, &student.FirstName, &student.LastName, &student.PreferredName,
+		&student.Grade, &student.StudentNumber, &student.ExternalID, &student.DateOfBirth, &student.Gender,
+		&address.Line1, &address.Line2, &address.City, &address.State, &address.PostalCode, &address.Country,
+		&student.EnrollmentDate, &student.Status, &student.GraduatedAt, &student.Version,
+		&student.CreatedAt, &student.UpdatedAt, &student.DeletedAt,
+	}, extra...)...)
+}
+
+// GetStudentsHandler returns one page of students. Pages are keyset based:
+// next_cursor is passed back as cursor, together with the same filters and
+// sort, to fetch the following page.
 func GetStudentsHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		utils.ErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
-	// Check for include_deleted query parameter
-	includeDeleted := r.URL.Query().Get("include_deleted") == "true"
-	log.Printf("GetStudentsHandler: include_deleted=%v, URL=%s", includeDeleted, r.URL.String())
-	
-	var query string
-	if includeDeleted {
-		query = `
-			SELECT id, name, grade, created_at, updated_at, deleted_at 
-			FROM students 
-			ORDER BY id
-		`
-		log.Println("Including deleted students in query")
-	} else {
-		query = `
-			SELECT id, name, grade, created_at, updated_at, deleted_at 
-			FROM students 
-			WHERE deleted_at IS NULL
-			ORDER BY id
-		`
-		log.Println("Excluding deleted students from query")
+	query := r.URL.Query()
+	filter, err := parseStudentFilter(query)
+	if err != nil {
+		utils.ErrorResponse(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	sort, err := parseStudentSort(query.Get("sort"))
+	if err != nil {
+		utils.ErrorResponse(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	cursor, err := sort.parseCursor(query.Get("cursor"))
+	if err != nil {
+		utils.ErrorResponse(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	limit, err := parsePageSize(query.Get("limit"))
+	if err != nil {
+		utils.ErrorResponse(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	conditions, args := filter.conditions(nil, nil)
+
+	var total int
+	if err := database.DB.QueryRow(`SELECT COUNT(*) FROM students `+whereClause(conditions), args...).Scan(&total); err != nil {
+		log.Printf("GetStudentsHandler: count error: %v", err)
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
 	}
 
-	rows, err := database.DB.Query(query)
+	if cursor != nil {
+		conditions, args = sort.after(cursor, conditions, args)
+	}
+	// One extra row tells us whether there is a next page.
+	args = append(args, limit+1)
+
+	rows, err := database.DB.Query(fmt.Sprintf(`
+		SELECT %s
+		FROM students
+		%s
+		ORDER BY %s
+		LIMIT $%d
+	`, studentColumns, whereClause(conditions), sort.orderBy(), len(args)), args...)
 	if err != nil {
-		log.Printf("Database query error: %v", err)
+		log.Printf("GetStudentsHandler: query error: %v", err)
 		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
 		return
 	}
 	defer rows.Close()
-	
-	log.Println("Query executed successfully")
 
-	var students []models.Student
+	students := []models.Student{}
 	for rows.Next() {
 		var student models.Student
-		err := rows.Scan(&student.ID, &student.Name, &student.Grade, 
-			&student.CreatedAt, &student.UpdatedAt, &student.DeletedAt)
-		if err != nil {
+		if err := scanStudent(rows, &student); err != nil {
 			utils.ErrorResponse(w, "Error scanning results", http.StatusInternalServerError)
 			return
 		}
-		log.Printf("Student: ID=%d, Name=%s, DeletedAt=%v", student.ID, student.Name, student.DeletedAt)
 		students = append(students, student)
 	}
 
@@ -70,12 +110,14 @@ func GetStudentsHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	log.Printf("Total students found: %d", len(students))
-	
-	response := models.StudentsResponse{
-		Students: students,
-		Count:    len(students),
+	response := models.StudentsResponse{Total: total}
+	if len(students) > limit {
+		students = students[:limit]
+		next := sort.cursorFor(students[limit-1])
+		response.NextCursor = &next
 	}
+	response.Students = students
+	response.Count = len(students)
 
 	utils.SuccessResponse(w, response, http.StatusOK)
 }
@@ -93,144 +135,545 @@ func CreateStudentHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Validate input
-	if err := utils.ValidateStudentName(createReq.Name); err != nil {
+	if err := validateStudentRequest(&createReq); err != nil {
 		utils.ErrorResponse(w, err.Error(), http.StatusBadRequest)
 		return
 	}
-	if err := utils.ValidateGrade(createReq.Grade); err != nil {
-		utils.ErrorResponse(w, err.Error(), http.StatusBadRequest)
+
+	student, err := insertStudent(database.DB, createReq)
+	if isUniqueViolation(err) {
+		utils.ErrorResponse(w, errStudentNumberTaken, http.StatusConflict)
+		return
+	} else if err != nil {
+		utils.ErrorResponse(w, "Failed to create student", http.StatusInternalServerError)
 		return
 	}
 
-	var student models.Student
-	now := time.Now()
-	err := database.DB.QueryRow(`
-		INSERT INTO students (name, grade, created_at, updated_at)
-		VALUES ($1, $2, $3, $4)
-		RETURNING id, name, grade, created_at, updated_at
-	`, createReq.Name, createReq.Grade, now, now).Scan(
-		&student.ID, &student.Name, &student.Grade, 
-		&student.CreatedAt, &student.UpdatedAt)
+	w.Header().Set("ETag", studentETag(student))
+	utils.SuccessResponse(w, student, http.StatusCreated)
+}
+
+const errStudentNumberTaken = "student_number is already assigned to another student"
+
+// validateStudentRequest trims and validates req, filling in name from
+// first_name and last_name or the other way round, and the default status.
+func validateStudentRequest(req *models.CreateStudentRequest) error {
+	for _, field := range []*string{
+		&req.Name, &req.FirstName, &req.LastName, &req.PreferredName, &req.StudentNumber,
+		&req.DateOfBirth, &req.Gender, &req.EnrollmentDate, &req.Status,
+		&req.Address.Line1, &req.Address.Line2, &req.Address.City, &req.Address.State,
+		&req.Address.PostalCode, &req.Address.Country,
+	} {
+		*field = strings.TrimSpace(*field)
+	}
+
+	if req.FirstName == "" && req.LastName == "" {
+		req.FirstName, req.LastName = utils.SplitStudentName(req.Name)
+	} else {
+		req.Name = strings.TrimSpace(req.FirstName + " " + req.LastName)
+	}
+	if req.Status == "" {
+		req.Status = models.StudentStatusActive
+	}
+
+	if err := utils.ValidateStudentName(req.Name); err != nil {
+		return err
+	}
+	if err := utils.ValidateFirstName(req.FirstName); err != nil {
+		return err
+	}
+	if err := utils.ValidateGrade(req.Grade); err != nil {
+		return err
+	}
+	if err := utils.ValidateStudentNumber(req.StudentNumber); err != nil {
+		return err
+	}
+	if err := utils.ValidateStudentStatus(req.Status); err != nil {
+		return err
+	}
 
+	dateOfBirth, err := utils.ParseDate("date_of_birth", req.DateOfBirth)
 	if err != nil {
-		utils.ErrorResponse(w, "Failed to create student", http.StatusInternalServerError)
+		return err
+	}
+	if dateOfBirth != nil {
+		if err := utils.ValidateDateOfBirth(*dateOfBirth); err != nil {
+			return err
+		}
+	}
+	if _, err := utils.ParseDate("enrollment_date", req.EnrollmentDate); err != nil {
+		return err
+	}
+
+	limits := []struct {
+		field, value string
+		max          int
+	}{
+		{"last_name", req.LastName, 255},
+		{"preferred_name", req.PreferredName, 100},
+		{"gender", req.Gender, 50},
+		{"address.line1", req.Address.Line1, 255},
+		{"address.line2", req.Address.Line2, 255},
+		{"address.city", req.Address.City, 100},
+		{"address.state", req.Address.State, 100},
+		{"address.postal_code", req.Address.PostalCode, 20},
+		{"address.country", req.Address.Country, 100},
+	}
+	for _, limit := range limits {
+		if err := utils.ValidateMaxLength(limit.field, limit.value, limit.max); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// studentETag identifies a revision of a student. The version is bumped on
+// every write, so it changes whenever the representation does.
+func studentETag(student models.Student) string {
+	return fmt.Sprintf(`"%d"`, student.Version)
+}
+
+func GetStudentHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.ErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
-	utils.SuccessResponse(w, student, http.StatusCreated)
+	studentID, err := parseIDParam(r, "id")
+	if err != nil {
+		utils.ErrorResponse(w, "Invalid student ID", http.StatusBadRequest)
+		return
+	}
+
+	var student models.Student
+	err = scanStudent(database.DB.QueryRow(`
+		SELECT `+studentColumns+`
+		FROM students
+		WHERE id = $1 AND deleted_at IS NULL
+	`, studentID), &student)
+
+	if err == sql.ErrNoRows {
+		utils.ErrorResponse(w, "Student not found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	etag := studentETag(student)
+	w.Header().Set("ETag", etag)
+	if ifNoneMatch := r.Header.Get("If-None-Match"); ifNoneMatch != "" && etagMatches(ifNoneMatch, etag, true) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	utils.SuccessResponse(w, student, http.StatusOK)
+}
+
+// lockStudentForWrite loads an active student inside tx and enforces the
+// If-Match precondition. It writes the error response and returns false when
+// the request must stop.
+func lockStudentForWrite(w http.ResponseWriter, r *http.Request, tx *sql.Tx, studentID int) (models.Student, bool) {
+	ifMatch := r.Header.Get("If-Match")
+	if ifMatch == "" {
+		utils.ErrorResponse(w, "If-Match header with the student's ETag is required", http.StatusPreconditionRequired)
+		return models.Student{}, false
+	}
+
+	student, err := lockStudent(tx, studentID)
+	if err == sql.ErrNoRows {
+		utils.ErrorResponse(w, "Student not found", http.StatusNotFound)
+		return student, false
+	} else if err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return student, false
+	}
+
+	if !etagMatches(ifMatch, studentETag(student), false) {
+		w.Header().Set("ETag", studentETag(student))
+		utils.ErrorResponse(w, "Student was modified by someone else; fetch it again and retry", http.StatusPreconditionFailed)
+		return student, false
+	}
+
+	return student, true
 }
 
+// UpdateStudentHandler serves the legacy PUT /students, which takes the ID
+// from the body. New clients should use PUT /students/{id}.
 func UpdateStudentHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPut {
 		utils.ErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
-	var student models.Student
-	if err := json.NewDecoder(r.Body).Decode(&student); err != nil {
+	var updateReq struct {
+		ID int `json:"id"`
+		models.CreateStudentRequest
+	}
+	if err := json.NewDecoder(r.Body).Decode(&updateReq); err != nil {
 		utils.ErrorResponse(w, "Invalid request body", http.StatusBadRequest)
 		return
 	}
 
-	// Validate input
-	if student.ID <= 0 {
+	if updateReq.ID <= 0 {
 		utils.ErrorResponse(w, "Invalid student ID", http.StatusBadRequest)
 		return
 	}
-	if err := utils.ValidateStudentName(student.Name); err != nil {
-		utils.ErrorResponse(w, err.Error(), http.StatusBadRequest)
+
+	writeStudentUpdate(w, r, updateReq.ID, updateReq.CreateStudentRequest)
+}
+
+// ReplaceStudentHandler replaces every editable field of a student.
+func ReplaceStudentHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		utils.ErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	studentID, err := parseIDParam(r, "id")
+	if err != nil {
+		utils.ErrorResponse(w, "Invalid student ID", http.StatusBadRequest)
+		return
+	}
+
+	var replaceReq models.CreateStudentRequest
+	if err := json.NewDecoder(r.Body).Decode(&replaceReq); err != nil {
+		utils.ErrorResponse(w, "Invalid request body", http.StatusBadRequest)
 		return
 	}
-	if err := utils.ValidateGrade(student.Grade); err != nil {
+
+	writeStudentUpdate(w, r, studentID, replaceReq)
+}
+
+func writeStudentUpdate(w http.ResponseWriter, r *http.Request, studentID int, req models.CreateStudentRequest) {
+	if err := validateStudentRequest(&req); err != nil {
 		utils.ErrorResponse(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	// Check if student exists
-	var exists bool
-	err := database.DB.QueryRow(`
-		SELECT EXISTS(SELECT 1 FROM students WHERE id = $1 AND deleted_at IS NULL)
-	`, student.ID).Scan(&exists)
-	
+	tx, err := database.DB.Begin()
 	if err != nil {
 		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
 		return
 	}
-	
-	if !exists {
-		utils.ErrorResponse(w, "Student not found", http.StatusNotFound)
+	defer tx.Rollback()
+
+	if _, ok := lockStudentForWrite(w, r, tx, studentID); !ok {
 		return
 	}
 
-	// Update the student
-	now := time.Now()
-	err = database.DB.QueryRow(`
-		UPDATE students 
-		SET name = $2, grade = $3, updated_at = $4
-		WHERE id = $1 AND deleted_at IS NULL
-		RETURNING id, name, grade, created_at, updated_at, deleted_at
-	`, student.ID, student.Name, student.Grade, now).Scan(
-		&student.ID, &student.Name, &student.Grade, 
-		&student.CreatedAt, &student.UpdatedAt, &student.DeletedAt)
-
-	if err != nil {
+	student, err := updateStudent(tx, studentID, req)
+	if isUniqueViolation(err) {
+		utils.ErrorResponse(w, errStudentNumberTaken, http.StatusConflict)
+		return
+	} else if err != nil {
+		log.Printf("UpdateStudentHandler: error=%v", err)
 		utils.ErrorResponse(w, "Failed to update student", http.StatusInternalServerError)
 		return
 	}
 
+	if err := tx.Commit(); err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("ETag", studentETag(student))
 	utils.SuccessResponse(w, student, http.StatusOK)
 }
 
-func DeleteStudentHandler(w http.ResponseWriter, r *http.Request) {
-	
-	// Get ID from mux vars or URL path
-	vars := mux.Vars(r)
-	studentID := vars["id"]
-	
-	// Fallback: extract from URL path if mux vars are empty
-	if studentID == "" {
-		parts := strings.Split(r.URL.Path, "/")
-		if len(parts) >= 3 && parts[1] == "students" {
-			studentID = parts[2]
-		}
+// PatchStudentHandler applies a JSON Merge Patch (RFC 7396) to a student, so
+// clients only send the fields they change.
+func PatchStudentHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPatch {
+		utils.ErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
 	}
-	
-	// Validate input
-	studentIDInt, err := strconv.Atoi(studentID)
-	if err != nil || studentIDInt <= 0 {
+
+	studentID, err := parseIDParam(r, "id")
+	if err != nil {
 		utils.ErrorResponse(w, "Invalid student ID", http.StatusBadRequest)
 		return
 	}
-	
-	// Check if student exists
-	var exists bool
-	err = database.DB.QueryRow(`
-		SELECT EXISTS(SELECT 1 FROM students WHERE id = $1 AND deleted_at IS NULL)
-	`, studentIDInt).Scan(&exists)
-	
+
+	if !isMergePatch(r) {
+		utils.ErrorResponse(w, "Content-Type must be application/merge-patch+json", http.StatusUnsupportedMediaType)
+		return
+	}
+
+	patch, err := io.ReadAll(r.Body)
 	if err != nil {
-		utils.ErrorResponse(w, "Not found", http.StatusNotFound)
+		utils.ErrorResponse(w, "Invalid request body", http.StatusBadRequest)
 		return
 	}
-	
-	if !exists {
-		utils.ErrorResponse(w, "Student not found", http.StatusNotFound)
+
+	tx, err := database.DB.Begin()
+	if err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	current, ok := lockStudentForWrite(w, r, tx, studentID)
+	if !ok {
+		return
+	}
+
+	patchReq, err := patchStudentRequest(current, patch)
+	if err != nil {
+		utils.ErrorResponse(w, "Invalid merge patch: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := validateStudentRequest(&patchReq); err != nil {
+		utils.ErrorResponse(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	student, err := updateStudent(tx, studentID, patchReq)
+	if isUniqueViolation(err) {
+		utils.ErrorResponse(w, errStudentNumberTaken, http.StatusConflict)
 		return
+	} else if err != nil {
+		log.Printf("PatchStudentHandler: error=%v", err)
+		utils.ErrorResponse(w, "Failed to update student", http.StatusInternalServerError)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("ETag", studentETag(student))
+	utils.SuccessResponse(w, student, http.StatusOK)
+}
+
+// patchStudentRequest applies patch to the editable fields of student.
+// Fields outside the request, such as id, are rejected. A patch that only
+// changes name splits it again into first_name and last_name.
+func patchStudentRequest(student models.Student, patch []byte) (models.CreateStudentRequest, error) {
+	var patched models.CreateStudentRequest
+
+	original := studentRequest(student)
+	current, err := json.Marshal(original)
+	if err != nil {
+		return patched, err
+	}
+	merged, err := mergePatch(current, patch)
+	if err != nil {
+		return patched, err
+	}
+
+	if err := decodeStrict(merged, &patched); err != nil {
+		return patched, err
+	}
+	if patched.Name != original.Name && patched.FirstName == original.FirstName && patched.LastName == original.LastName {
+		patched.FirstName, patched.LastName = "", ""
+	}
+	return patched, nil
+}
+
+// studentRequest returns the editable fields of student.
+func studentRequest(student models.Student) models.CreateStudentRequest {
+	req := models.CreateStudentRequest{
+		Name:           student.Name,
+		FirstName:      student.FirstName,
+		LastName:       student.LastName,
+		PreferredName:  stringValue(student.PreferredName),
+		Grade:          student.Grade,
+		StudentNumber:  stringValue(student.StudentNumber),
+		Gender:         stringValue(student.Gender),
+		Address:        student.Address,
+		EnrollmentDate: student.EnrollmentDate.Format("2006-01-02"),
+		Status:         student.Status,
+	}
+	if student.DateOfBirth != nil {
+		req.DateOfBirth = student.DateOfBirth.Format("2006-01-02")
+	}
+	return req
+}
+
+// studentValues returns the parameters shared by insertStudent and
+// updateStudent, which passes the student ID before them. Dates are validated already and cast by
+// Postgres.
+func studentValues(req models.CreateStudentRequest) []interface{} {
+	return []interface{}{
+		req.Name, req.FirstName, req.LastName, nullableString(req.PreferredName), req.Grade,
+		nullableString(req.StudentNumber), nullableString(req.DateOfBirth), nullableString(req.Gender),
+		req.Address.Line1, req.Address.Line2, req.Address.City, req.Address.State,
+		req.Address.PostalCode, req.Address.Country, nullableString(req.EnrollmentDate), req.Status,
+		time.Now(),
 	}
+}
+
+func insertStudent(db dbExecutor, req models.CreateStudentRequest) (models.Student, error) {
+	var student models.Student
+	err := scanStudent(db.QueryRow(`
+		INSERT INTO students (name, first_name, last_name, preferred_name, grade, student_number,
+		                      date_of_birth, gender, address_line1, address_line2, city, state,
+		                      postal_code, country, enrollment_date, status, graduated_at,
+		                      created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7::date, $8, $9, $10, $11, $12, $13, $14,
+		        COALESCE($15::date, CURRENT_DATE), $16,
+		        CASE WHEN $16::varchar = 'graduated' THEN CURRENT_DATE END, $17, $17)
+		RETURNING `+studentColumns,
+		studentValues(req)...), &student)
+	return student, err
+}
+
+// lockStudent loads an active student and locks its row until the end of the
+// transaction.
+func lockStudent(db dbExecutor, studentID int) (models.Student, error) {
+	var student models.Student
+	err := scanStudent(db.QueryRow(`
+		SELECT `+studentColumns+`
+		FROM students
+		WHERE id = $1 AND deleted_at IS NULL
+		FOR UPDATE
+	`, studentID), &student)
+	return student, err
+}
 
-	// Soft delete the student
+func softDeleteStudent(db dbExecutor, studentID int) error {
 	now := time.Now()
-	_, err = database.DB.Exec(`
-		UPDATE students 
-		SET deleted_at = $2, updated_at = $3
+	_, err := db.Exec(`
+		UPDATE students
+		SET deleted_at = $2, updated_at = $3, version = version + 1
+		WHERE id = $1 AND deleted_at IS NULL
+	`, studentID, now, now)
+	return err
+}
+
+// updateStudent replaces the editable fields of a student. Changing the
+// status keeps graduated_at in step: it is set when a student becomes
+// graduated and cleared otherwise.
+func updateStudent(db dbExecutor, studentID int, req models.CreateStudentRequest) (models.Student, error) {
+	var student models.Student
+	err := scanStudent(db.QueryRow(`
+		UPDATE students
+		SET name = $2, first_name = $3, last_name = $4, preferred_name = $5, grade = $6,
+		    student_number = $7, date_of_birth = $8::date, gender = $9,
+		    address_line1 = $10, address_line2 = $11, city = $12, state = $13,
+		    postal_code = $14, country = $15,
+		    enrollment_date = COALESCE($16::date, enrollment_date), status = $17,
+		    graduated_at = CASE WHEN $17::varchar = 'graduated' THEN COALESCE(graduated_at, CURRENT_DATE) END,
+		    updated_at = $18, version = version + 1
 		WHERE id = $1 AND deleted_at IS NULL
-	`, studentIDInt, now, now)
+		RETURNING `+studentColumns,
+		append([]interface{}{studentID}, studentValues(req)...)...), &student)
+	return student, err
+}
 
+func DeleteStudentHandler(w http.ResponseWriter, r *http.Request) {
+	studentID, err := parseIDParam(r, "id")
 	if err != nil {
-		utils.ErrorResponse(w, "Not found", http.StatusNotFound)
+		utils.ErrorResponse(w, "Invalid student ID", http.StatusBadRequest)
+		return
+	}
+
+	tx, err := database.DB.Begin()
+	if err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	if _, ok := lockStudentForWrite(w, r, tx, studentID); !ok {
+		return
+	}
+
+	if err := softDeleteStudent(tx, studentID); err != nil {
 		log.Printf("DeleteStudentHandler: error=%v", err)
+		utils.ErrorResponse(w, "Failed to delete student", http.StatusInternalServerError)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
 		return
 	}
 
 	utils.SuccessResponse(w, nil, http.StatusNoContent)
-}
\ No newline at end of file
+}
+
+func RestoreStudentHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.ErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	studentID, err := parseIDParam(r, "id")
+	if err != nil {
+		utils.ErrorResponse(w, "Invalid student ID", http.StatusBadRequest)
+		return
+	}
+
+	var student models.Student
+	err = scanStudent(database.DB.QueryRow(`
+		UPDATE students
+		SET deleted_at = NULL, updated_at = $2, version = version + 1
+		WHERE id = $1 AND deleted_at IS NOT NULL
+		RETURNING `+studentColumns,
+		studentID, time.Now()), &student)
+
+	if err == sql.ErrNoRows {
+		utils.ErrorResponse(w, "Deleted student not found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		log.Printf("RestoreStudentHandler: error=%v", err)
+		utils.ErrorResponse(w, "Failed to restore student", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("ETag", studentETag(student))
+	utils.SuccessResponse(w, student, http.StatusOK)
+}
+
+// PurgeStudentHandler permanently removes a student. Only students that were
+// already soft-deleted can be purged, so a purge always takes two steps.
+func PurgeStudentHandler(w http.ResponseWriter, r *http.Request) {
+	studentID, err := parseIDParam(r, "id")
+	if err != nil {
+		utils.ErrorResponse(w, "Invalid student ID", http.StatusBadRequest)
+		return
+	}
+
+	claims, _ := auth.GetUserFromContext(r.Context())
+
+	tx, err := database.DB.Begin()
+	if err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`DELETE FROM students WHERE id = $1 AND deleted_at IS NOT NULL`, studentID)
+	if err != nil {
+		log.Printf("PurgeStudentHandler: error=%v", err)
+		utils.ErrorResponse(w, "Failed to purge student", http.StatusInternalServerError)
+		return
+	}
+
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		utils.ErrorResponse(w, "Deleted student not found", http.StatusNotFound)
+		return
+	}
+
+	if err := audit.Record(tx, audit.Entry{
+		ActorID:    actorID(claims),
+		Action:     audit.ActionStudentPurged,
+		EntityType: "student",
+		EntityID:   strconv.Itoa(studentID),
+		IPAddress:  utils.ClientIP(r),
+	}); err != nil {
+		log.Printf("PurgeStudentHandler: error=%v", err)
+		utils.ErrorResponse(w, "Failed to purge student", http.StatusInternalServerError)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	utils.SuccessResponse(w, nil, http.StatusNoContent)
+}
@@ -0,0 +1,255 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Sea-Chels/go-practice-1/internal/database"
+	"github.com/Sea-Chels/go-practice-1/internal/models"
+	"github.com/Sea-Chels/go-practice-1/internal/utils"
+)
+
+const teacherColumns = `id, name, email, subject, hire_date, created_at, updated_at, deleted_at`
+
+func scanTeacher(row interface{ Scan(...interface{}) error }, teacher *models.Teacher) error {
+	return row.Scan(&teacher.ID, &teacher.Name, &teacher.Email, &teacher.Subject,
+		&teacher.HireDate, &teacher.CreatedAt, &teacher.UpdatedAt, &teacher.DeletedAt)
+}
+
+// validateTeacherRequest checks the request body and returns the parsed hire date.
+func validateTeacherRequest(req *models.CreateTeacherRequest) (*time.Time, error) {
+	req.Name = strings.TrimSpace(req.Name)
+	req.Email = strings.TrimSpace(req.Email)
+	req.Subject = strings.TrimSpace(req.Subject)
+
+	if err := utils.ValidateTeacherName(req.Name); err != nil {
+		return nil, err
+	}
+	if err := utils.ValidateEmail(req.Email); err != nil {
+		return nil, err
+	}
+	if err := utils.ValidateSubject(req.Subject); err != nil {
+		return nil, err
+	}
+	return utils.ParseDate("hire_date", req.HireDate)
+}
+
+func GetTeachersHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.ErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := `SELECT ` + teacherColumns + ` FROM teachers WHERE deleted_at IS NULL ORDER BY id`
+	if r.URL.Query().Get("include_deleted") == "true" {
+		query = `SELECT ` + teacherColumns + ` FROM teachers ORDER BY id`
+	}
+
+	rows, err := database.DB.Query(query)
+	if err != nil {
+		log.Printf("GetTeachersHandler: query error: %v", err)
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	teachers := []models.Teacher{}
+	for rows.Next() {
+		var teacher models.Teacher
+		if err := scanTeacher(rows, &teacher); err != nil {
+			utils.ErrorResponse(w, "Error scanning results", http.StatusInternalServerError)
+			return
+		}
+		teachers = append(teachers, teacher)
+	}
+
+	if err = rows.Err(); err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	response := models.TeachersResponse{
+		Teachers: teachers,
+		Count:    len(teachers),
+	}
+
+	utils.SuccessResponse(w, response, http.StatusOK)
+}
+
+func GetTeacherHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.ErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	teacherID, err := parseIDParam(r, "id")
+	if err != nil {
+		utils.ErrorResponse(w, "Invalid teacher ID", http.StatusBadRequest)
+		return
+	}
+
+	var teacher models.Teacher
+	err = scanTeacher(database.DB.QueryRow(`
+		SELECT `+teacherColumns+`
+		FROM teachers
+		WHERE id = $1 AND deleted_at IS NULL
+	`, teacherID), &teacher)
+
+	if err == sql.ErrNoRows {
+		utils.ErrorResponse(w, "Teacher not found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	utils.SuccessResponse(w, teacher, http.StatusOK)
+}
+
+func CreateTeacherHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.ErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var createReq models.CreateTeacherRequest
+	if err := json.NewDecoder(r.Body).Decode(&createReq); err != nil {
+		utils.ErrorResponse(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	hireDate, err := validateTeacherRequest(&createReq)
+	if err != nil {
+		utils.ErrorResponse(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var teacher models.Teacher
+	now := time.Now()
+	err = scanTeacher(database.DB.QueryRow(`
+		INSERT INTO teachers (name, email, subject, hire_date, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING `+teacherColumns,
+		createReq.Name, createReq.Email, nullableString(createReq.Subject), hireDate, now, now), &teacher)
+
+	if isUniqueViolation(err) {
+		utils.ErrorResponse(w, "A teacher with this email already exists", http.StatusConflict)
+		return
+	} else if err != nil {
+		log.Printf("CreateTeacherHandler: error=%v", err)
+		utils.ErrorResponse(w, "Failed to create teacher", http.StatusInternalServerError)
+		return
+	}
+
+	utils.SuccessResponse(w, teacher, http.StatusCreated)
+}
+
+func UpdateTeacherHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		utils.ErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	teacherID, err := parseIDParam(r, "id")
+	if err != nil {
+		utils.ErrorResponse(w, "Invalid teacher ID", http.StatusBadRequest)
+		return
+	}
+
+	var updateReq models.CreateTeacherRequest
+	if err := json.NewDecoder(r.Body).Decode(&updateReq); err != nil {
+		utils.ErrorResponse(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	hireDate, err := validateTeacherRequest(&updateReq)
+	if err != nil {
+		utils.ErrorResponse(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var teacher models.Teacher
+	err = scanTeacher(database.DB.QueryRow(`
+		UPDATE teachers
+		SET name = $2, email = $3, subject = $4, hire_date = $5, updated_at = $6
+		WHERE id = $1 AND deleted_at IS NULL
+		RETURNING `+teacherColumns,
+		teacherID, updateReq.Name, updateReq.Email, nullableString(updateReq.Subject), hireDate, time.Now()), &teacher)
+
+	if err == sql.ErrNoRows {
+		utils.ErrorResponse(w, "Teacher not found", http.StatusNotFound)
+		return
+	} else if isUniqueViolation(err) {
+		utils.ErrorResponse(w, "A teacher with this email already exists", http.StatusConflict)
+		return
+	} else if err != nil {
+		log.Printf("UpdateTeacherHandler: error=%v", err)
+		utils.ErrorResponse(w, "Failed to update teacher", http.StatusInternalServerError)
+		return
+	}
+
+	utils.SuccessResponse(w, teacher, http.StatusOK)
+}
+
+func DeleteTeacherHandler(w http.ResponseWriter, r *http.Request) {
+	teacherID, err := parseIDParam(r, "id")
+	if err != nil {
+		utils.ErrorResponse(w, "Invalid teacher ID", http.StatusBadRequest)
+		return
+	}
+
+	now := time.Now()
+	result, err := database.DB.Exec(`
+		UPDATE teachers
+		SET deleted_at = $2, updated_at = $3
+		WHERE id = $1 AND deleted_at IS NULL
+	`, teacherID, now, now)
+	if err != nil {
+		log.Printf("DeleteTeacherHandler: error=%v", err)
+		utils.ErrorResponse(w, "Failed to delete teacher", http.StatusInternalServerError)
+		return
+	}
+
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		utils.ErrorResponse(w, "Teacher not found", http.StatusNotFound)
+		return
+	}
+
+	utils.SuccessResponse(w, nil, http.StatusNoContent)
+}
+
+func RestoreTeacherHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.ErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	teacherID, err := parseIDParam(r, "id")
+	if err != nil {
+		utils.ErrorResponse(w, "Invalid teacher ID", http.StatusBadRequest)
+		return
+	}
+
+	var teacher models.Teacher
+	err = scanTeacher(database.DB.QueryRow(`
+		UPDATE teachers
+		SET deleted_at = NULL, updated_at = $2
+		WHERE id = $1 AND deleted_at IS NOT NULL
+		RETURNING `+teacherColumns,
+		teacherID, time.Now()), &teacher)
+
+	if err == sql.ErrNoRows {
+		utils.ErrorResponse(w, "Deleted teacher not found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		log.Printf("RestoreTeacherHandler: error=%v", err)
+		utils.ErrorResponse(w, "Failed to restore teacher", http.StatusInternalServerError)
+		return
+	}
+
+	utils.SuccessResponse(w, teacher, http.StatusOK)
+}
@@ -0,0 +1,384 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Sea-Chels/go-practice-1/internal/auth"
+	"github.com/Sea-Chels/go-practice-1/internal/database"
+	"github.com/Sea-Chels/go-practice-1/internal/models"
+	"github.com/Sea-Chels/go-practice-1/internal/utils"
+)
+
+const userColumns = `id, email, full_name, role, totp_enabled_at IS NOT NULL, created_at, updated_at, disabled_at, deleted_at`
+
+func scanUser(row interface{ Scan(...interface{}) error }, user *models.User) error {
+	return row.Scan(&user.ID, &user.Email, &user.FullName, &user.Role, &user.MFAEnabled,
+		&user.CreatedAt, &user.UpdatedAt, &user.DisabledAt, &user.DeletedAt)
+}
+
+// revokeUserTokens revokes every outstanding refresh token of the user.
+func revokeUserTokens(db dbExecutor, userID int) error {
+	_, err := db.Exec(`
+		UPDATE refresh_tokens
+		SET revoked_at = $2
+		WHERE user_id = $1 AND revoked_at IS NULL
+	`, userID, time.Now())
+	return err
+}
+
+// revokeUserAPIKeys revokes every API key the user created; keys act on the
+// creator's behalf and must not outlive their access.
+func revokeUserAPIKeys(db dbExecutor, userID int) error {
+	_, err := db.Exec(`
+		UPDATE api_keys
+		SET revoked_at = $2
+		WHERE created_by = $1 AND revoked_at IS NULL
+	`, userID, time.Now())
+	return err
+}
+
+func GetMeHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.ErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	claims, _ := auth.GetUserFromContext(r.Context())
+
+	var user models.User
+	err := scanUser(database.DB.QueryRow(`
+		SELECT `+userColumns+`
+		FROM users
+		WHERE id = $1 AND deleted_at IS NULL
+	`, claims.UserID), &user)
+
+	if err == sql.ErrNoRows {
+		utils.ErrorResponse(w, "User not found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	utils.SuccessResponse(w, user, http.StatusOK)
+}
+
+func UpdateMeHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPatch {
+		utils.ErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	claims, _ := auth.GetUserFromContext(r.Context())
+
+	var updateReq models.UpdateProfileRequest
+	if err := json.NewDecoder(r.Body).Decode(&updateReq); err != nil {
+		utils.ErrorResponse(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if updateReq.FullName != nil {
+		trimmed := strings.TrimSpace(*updateReq.FullName)
+		if err := utils.ValidateFullName(trimmed); err != nil {
+			utils.ErrorResponse(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		updateReq.FullName = &trimmed
+	}
+	if updateReq.Email != nil {
+		trimmed := strings.TrimSpace(*updateReq.Email)
+		if err := utils.ValidateEmail(trimmed); err != nil {
+			utils.ErrorResponse(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		updateReq.Email = &trimmed
+
+		var passwordHash string
+		err := database.DB.QueryRow(`
+			SELECT password_hash FROM users WHERE id = $1 AND deleted_at IS NULL
+		`, claims.UserID).Scan(&passwordHash)
+		if err == sql.ErrNoRows {
+			utils.ErrorResponse(w, "User not found", http.StatusNotFound)
+			return
+		} else if err != nil {
+			utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+			return
+		}
+		if updateReq.CurrentPassword == "" {
+			utils.ErrorResponse(w, "current_password is required to change the email", http.StatusBadRequest)
+			return
+		}
+		if !auth.CheckPassword(passwordHash, updateReq.CurrentPassword) {
+			utils.ErrorResponse(w, "Current password is incorrect", http.StatusUnauthorized)
+			return
+		}
+	}
+
+	// Fields left out of the request keep their current value.
+	var user models.User
+	err := scanUser(database.DB.QueryRow(`
+		UPDATE users
+		SET full_name = CASE WHEN $2 THEN NULLIF($3, '') ELSE full_name END,
+		    email = COALESCE($4, email),
+		    updated_at = $5
+		WHERE id = $1 AND deleted_at IS NULL
+		RETURNING `+userColumns,
+		claims.UserID, updateReq.FullName != nil, stringValue(updateReq.FullName), updateReq.Email, time.Now()), &user)
+
+	if err == sql.ErrNoRows {
+		utils.ErrorResponse(w, "User not found", http.StatusNotFound)
+		return
+	} else if isUniqueViolation(err) {
+		utils.ErrorResponse(w, "A user with this email already exists", http.StatusConflict)
+		return
+	} else if err != nil {
+		log.Printf("UpdateMeHandler: error=%v", err)
+		utils.ErrorResponse(w, "Failed to update profile", http.StatusInternalServerError)
+		return
+	}
+
+	utils.SuccessResponse(w, user, http.StatusOK)
+}
+
+func ChangePasswordHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.ErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	claims, _ := auth.GetUserFromContext(r.Context())
+
+	var changeReq models.ChangePasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&changeReq); err != nil {
+		utils.ErrorResponse(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := utils.ValidatePassword(changeReq.NewPassword); err != nil {
+		utils.ErrorResponse(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var passwordHash string
+	err := database.DB.QueryRow(`
+		SELECT password_hash FROM users WHERE id = $1 AND deleted_at IS NULL
+	`, claims.UserID).Scan(&passwordHash)
+
+	if err == sql.ErrNoRows {
+		utils.ErrorResponse(w, "User not found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	if !auth.CheckPassword(passwordHash, changeReq.CurrentPassword) {
+		utils.ErrorResponse(w, "Current password is incorrect", http.StatusUnauthorized)
+		return
+	}
+
+	newHash, err := auth.HashPassword(changeReq.NewPassword)
+	if err != nil {
+		utils.ErrorResponse(w, "Failed to update password", http.StatusInternalServerError)
+		return
+	}
+
+	tx, err := database.DB.Begin()
+	if err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`
+		UPDATE users SET password_hash = $2, updated_at = $3 WHERE id = $1
+	`, claims.UserID, newHash, time.Now()); err != nil {
+		utils.ErrorResponse(w, "Failed to update password", http.StatusInternalServerError)
+		return
+	}
+
+	// Sign out every session, the caller's included; access tokens already
+	// issued keep working until they expire.
+	if err := revokeUserTokens(tx, claims.UserID); err != nil {
+		utils.ErrorResponse(w, "Failed to update password", http.StatusInternalServerError)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	utils.SuccessResponse(w, nil, http.StatusNoContent)
+}
+
+func GetUsersHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.ErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := `SELECT ` + userColumns + ` FROM users WHERE deleted_at IS NULL ORDER BY id`
+	if r.URL.Query().Get("include_deleted") == "true" {
+		query = `SELECT ` + userColumns + ` FROM users ORDER BY id`
+	}
+
+	rows, err := database.DB.Query(query)
+	if err != nil {
+		log.Printf("GetUsersHandler: query error: %v", err)
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	users := []models.User{}
+	for rows.Next() {
+		var user models.User
+		if err := scanUser(rows, &user); err != nil {
+			utils.ErrorResponse(w, "Error scanning results", http.StatusInternalServerError)
+			return
+		}
+		users = append(users, user)
+	}
+
+	if err = rows.Err(); err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	utils.SuccessResponse(w, models.UsersResponse{Users: users, Count: len(users)}, http.StatusOK)
+}
+
+func DisableUserHandler(w http.ResponseWriter, r *http.Request) {
+	setUserDisabled(w, r, true)
+}
+
+func EnableUserHandler(w http.ResponseWriter, r *http.Request) {
+	setUserDisabled(w, r, false)
+}
+
+func setUserDisabled(w http.ResponseWriter, r *http.Request, disabled bool) {
+	if r.Method != http.MethodPost {
+		utils.ErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID, err := parseIDParam(r, "id")
+	if err != nil {
+		utils.ErrorResponse(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	claims, _ := auth.GetUserFromContext(r.Context())
+	if disabled && claims.UserID == userID {
+		utils.ErrorResponse(w, "You cannot disable your own account", http.StatusBadRequest)
+		return
+	}
+
+	tx, err := database.DB.Begin()
+	if err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	var disabledAt interface{}
+	if disabled {
+		disabledAt = now
+	}
+
+	var user models.User
+	err = scanUser(tx.QueryRow(`
+		UPDATE users
+		SET disabled_at = $2, updated_at = $3
+		WHERE id = $1 AND deleted_at IS NULL
+		RETURNING `+userColumns,
+		userID, disabledAt, now), &user)
+
+	if err == sql.ErrNoRows {
+		utils.ErrorResponse(w, "User not found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		utils.ErrorResponse(w, "Failed to update user", http.StatusInternalServerError)
+		return
+	}
+
+	if disabled {
+		if err := revokeUserTokens(tx, userID); err != nil {
+			utils.ErrorResponse(w, "Failed to update user", http.StatusInternalServerError)
+			return
+		}
+		if err := revokeUserAPIKeys(tx, userID); err != nil {
+			utils.ErrorResponse(w, "Failed to update user", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	utils.SuccessResponse(w, user, http.StatusOK)
+}
+
+func DeleteUserHandler(w http.ResponseWriter, r *http.Request) {
+	userID, err := parseIDParam(r, "id")
+	if err != nil {
+		utils.ErrorResponse(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	claims, _ := auth.GetUserFromContext(r.Context())
+	if claims.UserID == userID {
+		utils.ErrorResponse(w, "You cannot delete your own account", http.StatusBadRequest)
+		return
+	}
+
+	tx, err := database.DB.Begin()
+	if err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	result, err := tx.Exec(`
+		UPDATE users
+		SET deleted_at = $2, updated_at = $3
+		WHERE id = $1 AND deleted_at IS NULL
+	`, userID, now, now)
+	if err != nil {
+		log.Printf("DeleteUserHandler: error=%v", err)
+		utils.ErrorResponse(w, "Failed to delete user", http.StatusInternalServerError)
+		return
+	}
+
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		utils.ErrorResponse(w, "User not found", http.StatusNotFound)
+		return
+	}
+
+	if err := revokeUserTokens(tx, userID); err != nil {
+		utils.ErrorResponse(w, "Failed to delete user", http.StatusInternalServerError)
+		return
+	}
+
+	if err := revokeUserAPIKeys(tx, userID); err != nil {
+		utils.ErrorResponse(w, "Failed to delete user", http.StatusInternalServerError)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		utils.ErrorResponse(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	utils.SuccessResponse(w, nil, http.StatusNoContent)
+}
@@ -0,0 +1,40 @@
+package mailer
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// LogMailer writes messages to the application log, or appends them to Path
+// when it is set, instead of delivering them.
+type LogMailer struct {
+	Path string
+
+	mu sync.Mutex
+}
+
+func (m *LogMailer) Send(msg Message) error {
+	if m.Path == "" {
+		log.Printf("Mail to %s: %s\n%s", msg.To, msg.Subject, msg.Body)
+		return nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	file, err := os.OpenFile(m.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open mail log: %w", err)
+	}
+	defer file.Close()
+
+	_, err = fmt.Fprintf(file, "Date: %s\nTo: %s\nSubject: %s\n\n%s\n\n---\n\n",
+		time.Now().Format(time.RFC1123Z), msg.To, msg.Subject, msg.Body)
+	if err != nil {
+		return fmt.Errorf("failed to write mail log: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package mailer
+
+import (
+	"fmt"
+	"log"
+	"os"
+)
+
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer delivers plain text email.
+type Mailer interface {
+	Send(msg Message) error
+}
+
+// Default is the mailer used by the HTTP handlers. It is configured by Init.
+var Default Mailer = &LogMailer{}
+
+// Init configures Default from the environment. MAIL_DRIVER selects "smtp"
+// or "log". The log driver writes password reset links where anyone reading
+// the logs can use them, so it is only the default when ENV is development;
+// elsewhere MAIL_DRIVER must be set.
+func Init() error {
+	driver := os.Getenv("MAIL_DRIVER")
+	development := os.Getenv("ENV") == "development"
+	if driver == "" && !development {
+		return fmt.Errorf("MAIL_DRIVER must be set outside development (smtp or log)")
+	}
+
+	switch driver {
+	case "", "log":
+		if !development {
+			log.Printf("WARNING: MAIL_DRIVER=log outside development; password reset links are written to the log")
+		}
+		Default = &LogMailer{Path: os.Getenv("MAIL_LOG_FILE")}
+	case "smtp":
+		smtpMailer, err := NewSMTPMailerFromEnv()
+		if err != nil {
+			return err
+		}
+		Default = smtpMailer
+	default:
+		return fmt.Errorf("unknown MAIL_DRIVER %q", driver)
+	}
+
+	log.Printf("Mailer configured (driver: %s)", driverName(driver))
+	return nil
+}
+
+func driverName(driver string) string {
+	if driver == "" {
+		return "log"
+	}
+	return driver
+}
@@ -0,0 +1,61 @@
+package mailer
+
+import (
+	"fmt"
+	"net"
+	"net/smtp"
+	"os"
+	"strings"
+	"time"
+)
+
+type SMTPMailer struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+// NewSMTPMailerFromEnv reads SMTP_HOST, SMTP_PORT, SMTP_USERNAME,
+// SMTP_PASSWORD and MAIL_FROM.
+func NewSMTPMailerFromEnv() (*SMTPMailer, error) {
+	m := &SMTPMailer{
+		Host:     os.Getenv("SMTP_HOST"),
+		Port:     os.Getenv("SMTP_PORT"),
+		Username: os.Getenv("SMTP_USERNAME"),
+		Password: os.Getenv("SMTP_PASSWORD"),
+		From:     os.Getenv("MAIL_FROM"),
+	}
+	if m.Host == "" {
+		return nil, fmt.Errorf("SMTP_HOST not configured")
+	}
+	if m.From == "" {
+		return nil, fmt.Errorf("MAIL_FROM not configured")
+	}
+	if m.Port == "" {
+		m.Port = "587"
+	}
+	return m, nil
+}
+
+func (m *SMTPMailer) Send(msg Message) error {
+	// Refuse header injection through user-controlled values.
+	if strings.ContainsAny(msg.To, "\r\n") || strings.ContainsAny(msg.Subject, "\r\n") {
+		return fmt.Errorf("invalid message header")
+	}
+
+	var auth smtp.Auth
+	if m.Username != "" {
+		auth = smtp.PlainAuth("", m.Username, m.Password, m.Host)
+	}
+
+	body := fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\nDate: %s\r\nMIME-Version: 1.0\r\nContent-Type: text/plain; charset=UTF-8\r\n\r\n%s\r\n",
+		m.From, msg.To, msg.Subject, time.Now().Format(time.RFC1123Z), msg.Body)
+
+	addr := net.JoinHostPort(m.Host, m.Port)
+	if err := smtp.SendMail(addr, auth, m.From, []string{msg.To}, []byte(body)); err != nil {
+		return fmt.Errorf("failed to send mail: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+package models
+
+import (
+	"time"
+)
+
+type APIKey struct {
+	ID         int        `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	CreatedBy  *int       `json:"created_by,omitempty"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	// Key is only populated in the response to the request that created the
+	// key; the database keeps just its hash.
+	Key string `json:"key,omitempty"`
+}
+
+type CreateAPIKeyRequest struct {
+	Name      string     `json:"name"`
+	Scopes    []string   `json:"scopes"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+type APIKeysResponse struct {
+	APIKeys []APIKey `json:"api_keys"`
+	Count   int      `json:"count"`
+}
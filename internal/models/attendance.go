@@ -0,0 +1,104 @@
+package models
+
+import (
+	"time"
+)
+
+const (
+	AttendancePresent = "present"
+	AttendanceAbsent  = "absent"
+	AttendanceTardy   = "tardy"
+	AttendanceExcused = "excused"
+)
+
+type AttendanceRecord struct {
+	ID          int    `json:"id"`
+	StudentID   int    `json:"student_id"`
+	StudentName string `json:"student_name"`
+	// SectionID is nil for daily attendance.
+	SectionID  *int      `json:"section_id,omitempty"`
+	Date       time.Time `json:"date"`
+	Status     string    `json:"status"`
+	Notes      *string   `json:"notes,omitempty"`
+	RecordedBy *int      `json:"recorded_by,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+	// Corrections is only filled in when a single record is requested.
+	Corrections []AttendanceCorrection `json:"corrections,omitempty"`
+}
+
+type AttendanceCorrection struct {
+	ID             int       `json:"id"`
+	AttendanceID   int       `json:"attendance_id"`
+	PreviousStatus string    `json:"previous_status"`
+	NewStatus      string    `json:"new_status"`
+	Reason         string    `json:"reason"`
+	CorrectedBy    *int      `json:"corrected_by,omitempty"`
+	CorrectedAt    time.Time `json:"corrected_at"`
+}
+
+type AttendanceResponse struct {
+	Records []AttendanceRecord `json:"records"`
+	Count   int                `json:"count"`
+}
+
+// RosterAttendanceRequest records attendance for a whole class at once. With
+// SectionID every student must be enrolled in the section; without it the
+// records are daily attendance.
+type RosterAttendanceRequest struct {
+	Date      string                  `json:"date"`
+	SectionID *int                    `json:"section_id"`
+	Records   []RosterAttendanceEntry `json:"records"`
+}
+
+type RosterAttendanceEntry struct {
+	StudentID int    `json:"student_id"`
+	Status    string `json:"status"`
+	Notes     string `json:"notes"`
+}
+
+type RosterAttendanceResult struct {
+	Index     int               `json:"index"`
+	StudentID int               `json:"student_id"`
+	Created   bool              `json:"created"`
+	Error     string            `json:"error,omitempty"`
+	Record    *AttendanceRecord `json:"record,omitempty"`
+}
+
+type RosterAttendanceResponse struct {
+	Date      string                   `json:"date"`
+	SectionID *int                     `json:"section_id,omitempty"`
+	Created   int                      `json:"created"`
+	Unchanged int                      `json:"unchanged"`
+	Failed    int                      `json:"failed"`
+	Committed bool                     `json:"committed"`
+	Results   []RosterAttendanceResult `json:"results"`
+}
+
+// CorrectAttendanceRequest changes a recorded status. Notes replaces the
+// record's notes when given.
+type CorrectAttendanceRequest struct {
+	Status string  `json:"status"`
+	Notes  *string `json:"notes"`
+	Reason string  `json:"reason"`
+}
+
+// AttendanceSummary counts a student's records over a date range, either
+// daily attendance or that of one section.
+type AttendanceSummary struct {
+	StudentID int       `json:"student_id"`
+	SectionID *int      `json:"section_id,omitempty"`
+	From      time.Time `json:"from"`
+	To        time.Time `json:"to"`
+	// InstructionalDays counts the school days in the range according to
+	// the academic calendar.
+	InstructionalDays int `json:"instructional_days"`
+	Recorded          int `json:"recorded"`
+	Present           int `json:"present"`
+	Absent            int `json:"absent"`
+	Tardy             int `json:"tardy"`
+	Excused           int `json:"excused"`
+	// AttendanceRate is the share of recorded, unexcused days the student
+	// attended (present or tardy); nil when there are none.
+	AttendanceRate *float64 `json:"attendance_rate"`
+}
@@ -0,0 +1,22 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type AuditLog struct {
+	ID          int64           `json:"id"`
+	ActorUserID *int            `json:"actor_user_id,omitempty"`
+	Action      string          `json:"action"`
+	EntityType  string          `json:"entity_type"`
+	EntityID    *string         `json:"entity_id,omitempty"`
+	Details     json.RawMessage `json:"details,omitempty"`
+	IPAddress   *string         `json:"ip_address,omitempty"`
+	CreatedAt   time.Time       `json:"created_at"`
+}
+
+type AuditLogsResponse struct {
+	AuditLogs []AuditLog `json:"audit_logs"`
+	Count     int        `json:"count"`
+}
@@ -0,0 +1,87 @@
+package models
+
+import (
+	"time"
+)
+
+const (
+	TermTypeSemester  = "semester"
+	TermTypeTrimester = "trimester"
+	TermTypeQuarter   = "quarter"
+	TermTypeOther     = "other"
+)
+
+type AcademicYear struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	StartDate time.Time `json:"start_date"`
+	EndDate   time.Time `json:"end_date"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	// Terms and Events are only filled in when a single year is requested.
+	Terms  []Term          `json:"terms,omitempty"`
+	Events []CalendarEvent `json:"events,omitempty"`
+}
+
+// CreateAcademicYearRequest takes YYYY-MM-DD dates; both days are part of
+// the year.
+type CreateAcademicYearRequest struct {
+	Name      string `json:"name"`
+	StartDate string `json:"start_date"`
+	EndDate   string `json:"end_date"`
+}
+
+type AcademicYearsResponse struct {
+	AcademicYears []AcademicYear `json:"academic_years"`
+	Count         int            `json:"count"`
+}
+
+type Term struct {
+	ID             int       `json:"id"`
+	AcademicYearID int       `json:"academic_year_id"`
+	Name           string    `json:"name"`
+	Type           string    `json:"type"`
+	StartDate      time.Time `json:"start_date"`
+	EndDate        time.Time `json:"end_date"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+type CreateTermRequest struct {
+	AcademicYearID int    `json:"academic_year_id"`
+	Name           string `json:"name"`
+	Type           string `json:"type"`
+	StartDate      string `json:"start_date"`
+	EndDate        string `json:"end_date"`
+}
+
+type TermsResponse struct {
+	Terms []Term `json:"terms"`
+	Count int    `json:"count"`
+}
+
+type CalendarEvent struct {
+	ID             int       `json:"id"`
+	AcademicYearID int       `json:"academic_year_id"`
+	Name           string    `json:"name"`
+	Type           string    `json:"type"`
+	StartDate      time.Time `json:"start_date"`
+	EndDate        time.Time `json:"end_date"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// CreateCalendarEventRequest describes a holiday or non-instructional day.
+// EndDate defaults to StartDate for single days.
+type CreateCalendarEventRequest struct {
+	AcademicYearID int    `json:"academic_year_id"`
+	Name           string `json:"name"`
+	Type           string `json:"type"`
+	StartDate      string `json:"start_date"`
+	EndDate        string `json:"end_date"`
+}
+
+type CalendarEventsResponse struct {
+	Events []CalendarEvent `json:"events"`
+	Count  int             `json:"count"`
+}
@@ -0,0 +1,110 @@
+package models
+
+import (
+	"time"
+)
+
+type Course struct {
+	ID          int        `json:"id"`
+	Code        string     `json:"code"`
+	Name        string     `json:"name"`
+	Description *string    `json:"description,omitempty"`
+	Credits     float64    `json:"credits"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	DeletedAt   *time.Time `json:"deleted_at,omitempty"`
+}
+
+type CreateCourseRequest struct {
+	Code        string  `json:"code"`
+	Name        string  `json:"name"`
+	Description string  `json:"description"`
+	Credits     float64 `json:"credits"`
+}
+
+type CoursesResponse struct {
+	Courses []Course `json:"courses"`
+	Count   int      `json:"count"`
+}
+
+type Section struct {
+	ID            int     `json:"id"`
+	CourseID      int     `json:"course_id"`
+	CourseCode    string  `json:"course_code"`
+	CourseName    string  `json:"course_name"`
+	TeacherID     *int    `json:"teacher_id,omitempty"`
+	TeacherName   *string `json:"teacher_name,omitempty"`
+	SectionNumber string  `json:"section_number"`
+	Term          string  `json:"term"`
+	TermID        *int    `json:"term_id,omitempty"`
+	Room          *string `json:"room,omitempty"`
+	Capacity      int     `json:"capacity"`
+	Enrolled      int     `json:"enrolled"`
+	// GradingScaleID is nil when the section uses the default scale.
+	GradingScaleID *int `json:"grading_scale_id,omitempty"`
+	// MeetingDays uses one letter per day: M T W R F S U.
+	MeetingDays *string    `json:"meeting_days,omitempty"`
+	StartTime   *string    `json:"start_time,omitempty"`
+	EndTime     *string    `json:"end_time,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	DeletedAt   *time.Time `json:"deleted_at,omitempty"`
+}
+
+type CreateSectionRequest struct {
+	CourseID      int    `json:"course_id"`
+	TeacherID     *int   `json:"teacher_id"`
+	SectionNumber string `json:"section_number"`
+	// Term is a free-text label. With TermID it defaults to the academic
+	// year and term names.
+	Term        string `json:"term"`
+	TermID      *int   `json:"term_id"`
+	Room        string `json:"room"`
+	Capacity    int    `json:"capacity"`
+	MeetingDays string `json:"meeting_days"`
+	// Sections without a GradingScaleID use the default grading scale.
+	GradingScaleID *int `json:"grading_scale_id"`
+	// StartTime and EndTime are HH:MM in 24-hour time.
+	StartTime string `json:"start_time"`
+	EndTime   string `json:"end_time"`
+}
+
+type SectionsResponse struct {
+	Sections []Section `json:"sections"`
+	Count    int       `json:"count"`
+}
+
+type Enrollment struct {
+	ID         int        `json:"id"`
+	SectionID  int        `json:"section_id"`
+	StudentID  int        `json:"student_id"`
+	Status     string     `json:"status"`
+	EnrolledAt time.Time  `json:"enrolled_at"`
+	DroppedAt  *time.Time `json:"dropped_at,omitempty"`
+}
+
+type EnrollStudentRequest struct {
+	StudentID int `json:"student_id"`
+}
+
+type RosterEntry struct {
+	StudentID     int       `json:"student_id"`
+	Name          string    `json:"name"`
+	StudentNumber *string   `json:"student_number,omitempty"`
+	Grade         int       `json:"grade"`
+	EnrolledAt    time.Time `json:"enrolled_at"`
+}
+
+type SectionRosterResponse struct {
+	Section  Section       `json:"section"`
+	Students []RosterEntry `json:"students"`
+	Count    int           `json:"count"`
+}
+
+type StudentScheduleResponse struct {
+	StudentID int       `json:"student_id"`
+	Term      string    `json:"term,omitempty"`
+	TermID    *int      `json:"term_id,omitempty"`
+	Sections  []Section `json:"sections"`
+	Count     int       `json:"count"`
+}
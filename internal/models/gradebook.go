@@ -0,0 +1,153 @@
+package models
+
+import (
+	"time"
+)
+
+// GradeBand maps averages of at least MinPercent to Letter.
+type GradeBand struct {
+	Letter     string  `json:"letter"`
+	MinPercent float64 `json:"min_percent"`
+}
+
+type GradingScale struct {
+	ID        int    `json:"id"`
+	Name      string `json:"name"`
+	IsDefault bool   `json:"is_default"`
+	// Bands are ordered from the highest MinPercent down.
+	Bands     []GradeBand `json:"bands"`
+	CreatedAt time.Time   `json:"created_at"`
+	UpdatedAt time.Time   `json:"updated_at"`
+}
+
+type CreateGradingScaleRequest struct {
+	Name      string      `json:"name"`
+	IsDefault bool        `json:"is_default"`
+	Bands     []GradeBand `json:"bands"`
+}
+
+type GradingScalesResponse struct {
+	GradingScales []GradingScale `json:"grading_scales"`
+	Count         int            `json:"count"`
+}
+
+// GradeCategory groups the assignments of a section, such as homework or
+// exams. Weights are relative to the other categories of the section.
+type GradeCategory struct {
+	ID        int       `json:"id"`
+	SectionID int       `json:"section_id"`
+	Name      string    `json:"name"`
+	Weight    float64   `json:"weight"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type CreateGradeCategoryRequest struct {
+	Name   string  `json:"name"`
+	Weight float64 `json:"weight"`
+}
+
+type GradeCategoriesResponse struct {
+	Categories  []GradeCategory `json:"categories"`
+	TotalWeight float64         `json:"total_weight"`
+	Count       int             `json:"count"`
+}
+
+type Assignment struct {
+	ID           int        `json:"id"`
+	SectionID    int        `json:"section_id"`
+	CategoryID   int        `json:"category_id"`
+	CategoryName string     `json:"category_name"`
+	Title        string     `json:"title"`
+	Description  *string    `json:"description,omitempty"`
+	MaxPoints    float64    `json:"max_points"`
+	DueDate      *time.Time `json:"due_date,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+// CreateAssignmentRequest takes an optional YYYY-MM-DD due date.
+type CreateAssignmentRequest struct {
+	CategoryID  int     `json:"category_id"`
+	Title       string  `json:"title"`
+	Description string  `json:"description"`
+	MaxPoints   float64 `json:"max_points"`
+	DueDate     string  `json:"due_date"`
+}
+
+type AssignmentsResponse struct {
+	Assignments []Assignment `json:"assignments"`
+	Count       int          `json:"count"`
+}
+
+// Score is a student's mark on an assignment. Students who have not been
+// scored yet are listed without UpdatedAt.
+type Score struct {
+	AssignmentID int        `json:"assignment_id"`
+	StudentID    int        `json:"student_id"`
+	StudentName  string     `json:"student_name"`
+	Points       *float64   `json:"points"`
+	Late         bool       `json:"late"`
+	Missing      bool       `json:"missing"`
+	Exempt       bool       `json:"exempt"`
+	Comment      *string    `json:"comment,omitempty"`
+	GradedBy     *int       `json:"graded_by,omitempty"`
+	UpdatedAt    *time.Time `json:"updated_at,omitempty"`
+}
+
+// ScoreEntry sets a student's mark. Missing work counts as zero and cannot
+// have points; exempt work does not count at all.
+type ScoreEntry struct {
+	StudentID int      `json:"student_id"`
+	Points    *float64 `json:"points"`
+	Late      bool     `json:"late"`
+	Missing   bool     `json:"missing"`
+	Exempt    bool     `json:"exempt"`
+	Comment   string   `json:"comment"`
+}
+
+type RecordScoresRequest struct {
+	Scores []ScoreEntry `json:"scores"`
+}
+
+type AssignmentScoresResponse struct {
+	Assignment Assignment `json:"assignment"`
+	Scores     []Score    `json:"scores"`
+	Count      int        `json:"count"`
+}
+
+// CategoryGrade is a student's average in one category. Percent is nil
+// while nothing in the category has been graded.
+type CategoryGrade struct {
+	CategoryID int      `json:"category_id"`
+	Name       string   `json:"name"`
+	Weight     float64  `json:"weight"`
+	Percent    *float64 `json:"percent"`
+	Graded     int      `json:"graded"`
+}
+
+// StudentGrade is a student's running average in a section, weighted by
+// category over the graded categories only.
+type StudentGrade struct {
+	StudentID   int             `json:"student_id"`
+	StudentName string          `json:"student_name,omitempty"`
+	SectionID   int             `json:"section_id"`
+	CourseCode  string          `json:"course_code,omitempty"`
+	CourseName  string          `json:"course_name,omitempty"`
+	Percent     *float64        `json:"percent"`
+	Letter      *string         `json:"letter"`
+	Categories  []CategoryGrade `json:"categories"`
+}
+
+type SectionGradesResponse struct {
+	Section      Section        `json:"section"`
+	GradingScale *GradingScale  `json:"grading_scale"`
+	Students     []StudentGrade `json:"students"`
+	Count        int            `json:"count"`
+}
+
+type StudentGradesResponse struct {
+	StudentID int            `json:"student_id"`
+	Sections  []StudentGrade `json:"sections"`
+	Count     int            `json:"count"`
+}
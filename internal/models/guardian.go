@@ -0,0 +1,80 @@
+package models
+
+import (
+	"time"
+)
+
+type Guardian struct {
+	ID             int        `json:"id"`
+	FirstName      string     `json:"first_name"`
+	LastName       string     `json:"last_name"`
+	Email          *string    `json:"email,omitempty"`
+	Phone          string     `json:"phone"`
+	AlternatePhone *string    `json:"alternate_phone,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+	DeletedAt      *time.Time `json:"deleted_at,omitempty"`
+}
+
+type CreateGuardianRequest struct {
+	FirstName      string `json:"first_name"`
+	LastName       string `json:"last_name"`
+	Email          string `json:"email"`
+	Phone          string `json:"phone"`
+	AlternatePhone string `json:"alternate_phone"`
+}
+
+type GuardiansResponse struct {
+	Guardians []Guardian `json:"guardians"`
+	Count     int        `json:"count"`
+}
+
+// GuardianLink describes how a guardian relates to one student.
+type GuardianLink struct {
+	Relationship     string `json:"relationship"`
+	HasCustody       bool   `json:"has_custody"`
+	CanPickup        bool   `json:"can_pickup"`
+	EmergencyContact bool   `json:"emergency_contact"`
+	// Priority orders the contacts of a student, 1 is called first. It is
+	// unique per student.
+	Priority int `json:"priority"`
+}
+
+// LinkGuardianRequest creates or replaces the link between a student and a
+// guardian. Omitted flags default to no custody, no pickup and emergency
+// contact. Without a priority an existing link keeps its place and a new one
+// goes last.
+type LinkGuardianRequest struct {
+	Relationship     string `json:"relationship"`
+	HasCustody       *bool  `json:"has_custody"`
+	CanPickup        *bool  `json:"can_pickup"`
+	EmergencyContact *bool  `json:"emergency_contact"`
+	Priority         *int   `json:"priority"`
+}
+
+// StudentGuardian is a guardian as listed for a student.
+type StudentGuardian struct {
+	Guardian
+	GuardianLink
+}
+
+type StudentGuardiansResponse struct {
+	StudentID int               `json:"student_id"`
+	Guardians []StudentGuardian `json:"guardians"`
+	Count     int               `json:"count"`
+}
+
+// GuardianStudent is a student as listed for a guardian.
+type GuardianStudent struct {
+	StudentID int    `json:"student_id"`
+	Name      string `json:"name"`
+	Grade     int    `json:"grade"`
+	Status    string `json:"status"`
+	GuardianLink
+}
+
+type GuardianStudentsResponse struct {
+	GuardianID int               `json:"guardian_id"`
+	Students   []GuardianStudent `json:"students"`
+	Count      int               `json:"count"`
+}
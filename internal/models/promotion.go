@@ -0,0 +1,57 @@
+package models
+
+import (
+	"time"
+)
+
+type PromotionRequest struct {
+	// HoldBack lists students who stay in their current grade.
+	HoldBack []int `json:"hold_back"`
+	// GraduationDate (YYYY-MM-DD) is stored on graduating students; it
+	// defaults to today.
+	GraduationDate string `json:"graduation_date"`
+	// Force allows a run within 30 days of the previous one.
+	Force bool `json:"force"`
+}
+
+type PromotionStudent struct {
+	ID    int    `json:"id"`
+	Name  string `json:"name"`
+	Grade int    `json:"grade"`
+}
+
+type PromotionGradeCount struct {
+	Grade     int `json:"grade"`
+	Students  int `json:"students"`
+	NextGrade int `json:"next_grade"`
+}
+
+type PromotionPreview struct {
+	GraduationDate string                `json:"graduation_date"`
+	Promoted       int                   `json:"promoted"`
+	Graduated      int                   `json:"graduated"`
+	HeldBack       int                   `json:"held_back"`
+	ByGrade        []PromotionGradeCount `json:"by_grade"`
+	Graduating     []PromotionStudent    `json:"graduating"`
+	HeldBackList   []PromotionStudent    `json:"held_back_students"`
+}
+
+type PromotionRun struct {
+	ID             int        `json:"id"`
+	GraduationDate time.Time  `json:"graduation_date"`
+	Promoted       int        `json:"promoted"`
+	Graduated      int        `json:"graduated"`
+	HeldBack       int        `json:"held_back"`
+	ExecutedBy     *int       `json:"executed_by,omitempty"`
+	ExecutedAt     time.Time  `json:"executed_at"`
+	RevertedBy     *int       `json:"reverted_by,omitempty"`
+	RevertedAt     *time.Time `json:"reverted_at,omitempty"`
+	// Skipped is set when reverting: students changed since the run are
+	// left as they are.
+	Skipped int `json:"skipped,omitempty"`
+}
+
+type PromotionRunsResponse struct {
+	Runs  []PromotionRun `json:"runs"`
+	Count int            `json:"count"`
+}
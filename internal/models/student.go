@@ -1,24 +1,132 @@
 package models
 
 import (
+	"encoding/json"
 	"time"
 )
 
+const (
+	StudentStatusActive    = "active"
+	StudentStatusWithdrawn = "withdrawn"
+	StudentStatusGraduated = "graduated"
+)
+
 type Student struct {
-	ID        int        `json:"id"`
-	Name      string     `json:"name"`
-	Grade     int        `json:"grade"`
-	CreatedAt time.Time  `json:"created_at"`
-	UpdatedAt time.Time  `json:"updated_at"`
-	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+	ID int `json:"id"`
+	// Name is the full name, first_name followed by last_name.
+	Name           string         `json:"name"`
+	FirstName      string         `json:"first_name"`
+	LastName       string         `json:"last_name"`
+	PreferredName  *string        `json:"preferred_name,omitempty"`
+	Grade          int            `json:"grade"`
+	StudentNumber  *string        `json:"student_number,omitempty"`
+	ExternalID     *string        `json:"external_id,omitempty"`
+	DateOfBirth    *time.Time     `json:"date_of_birth,omitempty"`
+	Gender         *string        `json:"gender,omitempty"`
+	Address        StudentAddress `json:"address"`
+	EnrollmentDate time.Time      `json:"enrollment_date"`
+	Status         string         `json:"status"`
+	GraduatedAt    *time.Time     `json:"graduated_at,omitempty"`
+	Version        int            `json:"version"`
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+	DeletedAt      *time.Time     `json:"deleted_at,omitempty"`
 }
 
+type StudentAddress struct {
+	Line1      string `json:"line1"`
+	Line2      string `json:"line2"`
+	City       string `json:"city"`
+	State      string `json:"state"`
+	PostalCode string `json:"postal_code"`
+	Country    string `json:"country"`
+}
+
+// CreateStudentRequest is used to create and replace students. Either name or
+// first_name and last_name may be given; name is split at the first space
+// when only it is sent.
 type CreateStudentRequest struct {
-	Name  string `json:"name"`
-	Grade int    `json:"grade"`
+	Name          string `json:"name"`
+	FirstName     string `json:"first_name"`
+	LastName      string `json:"last_name"`
+	PreferredName string `json:"preferred_name"`
+	Grade         int    `json:"grade"`
+	StudentNumber string `json:"student_number"`
+	// DateOfBirth and EnrollmentDate are YYYY-MM-DD dates. EnrollmentDate
+	// defaults to today for new students and is kept when replacing.
+	DateOfBirth    string         `json:"date_of_birth"`
+	Gender         string         `json:"gender"`
+	Address        StudentAddress `json:"address"`
+	EnrollmentDate string         `json:"enrollment_date"`
+	// Status defaults to active.
+	Status string `json:"status"`
 }
 
 type StudentsResponse struct {
-	Students []Student `json:"students"`
-	Count    int       `json:"count"`
-}
\ No newline at end of file
+	Students   []Student `json:"students"`
+	Count      int       `json:"count"`
+	Total      int       `json:"total"`
+	NextCursor *string   `json:"next_cursor"`
+}
+type StudentSearchResult struct {
+	Student
+	Score float64 `json:"score"`
+	// Highlight is the HTML-escaped name with matching words wrapped in <mark>.
+	Highlight string `json:"highlight"`
+}
+
+type StudentSearchResponse struct {
+	Query   string                `json:"query"`
+	Results []StudentSearchResult `json:"results"`
+	Count   int                   `json:"count"`
+}
+
+// StudentImportRowError lists why one CSV row was not imported. Row is the
+// line number in the file, counting the header as line 1.
+type StudentImportRowError struct {
+	Row        int      `json:"row"`
+	ExternalID string   `json:"external_id,omitempty"`
+	Errors     []string `json:"errors"`
+}
+
+type StudentImportReport struct {
+	DryRun    bool                    `json:"dry_run"`
+	TotalRows int                     `json:"total_rows"`
+	Created   int                     `json:"created"`
+	Updated   int                     `json:"updated"`
+	Failed    int                     `json:"failed"`
+	Errors    []StudentImportRowError `json:"errors"`
+}
+
+// StudentBatchOperation is one step of POST /students/batch. Op is create,
+// update or delete. Data holds the new student for create and a JSON Merge
+// Patch for update; update and delete require the current version.
+type StudentBatchOperation struct {
+	Op      string          `json:"op"`
+	ID      int             `json:"id,omitempty"`
+	Version int             `json:"version,omitempty"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+type StudentBatchRequest struct {
+	// Atomic (the default) rolls back every operation if one fails.
+	Atomic     *bool                   `json:"atomic"`
+	Operations []StudentBatchOperation `json:"operations"`
+}
+
+type StudentBatchResult struct {
+	Index   int      `json:"index"`
+	Op      string   `json:"op"`
+	ID      int      `json:"id,omitempty"`
+	Status  int      `json:"status"`
+	Student *Student `json:"student,omitempty"`
+	Error   string   `json:"error,omitempty"`
+}
+
+type StudentBatchResponse struct {
+	Atomic    bool                 `json:"atomic"`
+	Committed bool                 `json:"committed"`
+	Succeeded int                  `json:"succeeded"`
+	Failed    int                  `json:"failed"`
+	Results   []StudentBatchResult `json:"results"`
+}
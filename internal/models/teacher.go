@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+)
+
+type Teacher struct {
+	ID        int        `json:"id"`
+	Name      string     `json:"name"`
+	Email     string     `json:"email"`
+	Subject   *string    `json:"subject,omitempty"`
+	HireDate  *time.Time `json:"hire_date,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+}
+
+type CreateTeacherRequest struct {
+	Name     string `json:"name"`
+	Email    string `json:"email"`
+	Subject  string `json:"subject"`
+	HireDate string `json:"hire_date"`
+}
+
+type TeachersResponse struct {
+	Teachers []Teacher `json:"teachers"`
+	Count    int       `json:"count"`
+}
@@ -7,18 +7,118 @@ import (
 type User struct {
 	ID           int        `json:"id"`
 	Email        string     `json:"email"`
+	FullName     *string    `json:"full_name,omitempty"`
 	PasswordHash string     `json:"-"`
+	Role         string     `json:"role"`
+	MFAEnabled   bool       `json:"mfa_enabled"`
 	CreatedAt    time.Time  `json:"created_at"`
 	UpdatedAt    time.Time  `json:"updated_at"`
+	DisabledAt   *time.Time `json:"disabled_at,omitempty"`
 	DeletedAt    *time.Time `json:"deleted_at,omitempty"`
 }
 
+type UsersResponse struct {
+	Users []User `json:"users"`
+	Count int    `json:"count"`
+}
+
+// UpdateProfileRequest needs CurrentPassword whenever Email is set, as the
+// email is what the user signs in with.
+type UpdateProfileRequest struct {
+	FullName        *string `json:"full_name"`
+	Email           *string `json:"email"`
+	CurrentPassword string  `json:"current_password"`
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password"`
+	NewPassword     string `json:"new_password"`
+}
+
+type Invitation struct {
+	ID         int        `json:"id"`
+	Email      string     `json:"email"`
+	FullName   *string    `json:"full_name,omitempty"`
+	Role       string     `json:"role"`
+	InvitedBy  *int       `json:"invited_by,omitempty"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	AcceptedAt *time.Time `json:"accepted_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	// Token is only populated in the response to the request that created
+	// the invitation; the database keeps just its hash.
+	Token string `json:"token,omitempty"`
+}
+
+type CreateInvitationRequest struct {
+	Email    string `json:"email"`
+	FullName string `json:"full_name"`
+	Role     string `json:"role"`
+}
+
+type InvitationsResponse struct {
+	Invitations []Invitation `json:"invitations"`
+	Count       int          `json:"count"`
+}
+
+type AcceptInvitationRequest struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
+	FullName string `json:"full_name"`
+}
+
 type LoginRequest struct {
 	Email    string `json:"email"`
 	Password string `json:"password"`
 }
 
 type LoginResponse struct {
-	Token     string    `json:"token"`
-	ExpiresAt time.Time `json:"expires_at"`
-}
\ No newline at end of file
+	Token            string    `json:"token"`
+	ExpiresAt        time.Time `json:"expires_at"`
+	RefreshToken     string    `json:"refresh_token"`
+	RefreshExpiresAt time.Time `json:"refresh_expires_at"`
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+type ForgotPasswordRequest struct {
+	Email string `json:"email"`
+}
+
+type ResetPasswordRequest struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
+}
+
+// MFAChallengeResponse is returned by the login endpoint instead of a
+// LoginResponse when the user has two-factor authentication enabled.
+type MFAChallengeResponse struct {
+	MFARequired bool      `json:"mfa_required"`
+	MFAToken    string    `json:"mfa_token"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+type MFAVerifyRequest struct {
+	MFAToken     string `json:"mfa_token"`
+	Code         string `json:"code"`
+	RecoveryCode string `json:"recovery_code"`
+}
+
+type MFAEnrollResponse struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+type MFACodeRequest struct {
+	Code string `json:"code"`
+}
+
+type MFARecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+type MFADisableRequest struct {
+	Password string `json:"password"`
+	Code     string `json:"code"`
+}
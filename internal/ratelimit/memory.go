@@ -0,0 +1,57 @@
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+const sweepInterval = time.Minute
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+	period time.Duration
+}
+
+// MemoryStore keeps buckets in process memory. Limits are per instance.
+type MemoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{buckets: map[string]*bucket{}}
+}
+
+func (s *MemoryStore) Take(key string, policy Policy, now time.Time) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if now.Sub(s.lastSweep) > sweepInterval {
+		s.sweep(now)
+	}
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(policy.Limit), last: now, period: policy.Period}
+		s.buckets[key] = b
+	}
+
+	var result Result
+	b.tokens, result = refill(b.tokens, b.last, policy, now)
+	b.last = now
+	b.period = policy.Period
+	return result, nil
+}
+
+// sweep drops buckets idle long enough to have refilled completely; they
+// are indistinguishable from new ones.
+func (s *MemoryStore) sweep(now time.Time) {
+	for key, b := range s.buckets {
+		if now.Sub(b.last) > b.period {
+			delete(s.buckets, key)
+		}
+	}
+	s.lastSweep = now
+}
@@ -0,0 +1,61 @@
+package ratelimit
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Sea-Chels/go-practice-1/internal/database"
+)
+
+// PostgresStore keeps buckets in the rate_limit_buckets table so that every
+// API instance shares the same limits.
+type PostgresStore struct{}
+
+func NewPostgresStore() *PostgresStore {
+	return &PostgresStore{}
+}
+
+func (s *PostgresStore) Take(key string, policy Policy, now time.Time) (Result, error) {
+	tx, err := database.DB.Begin()
+	if err != nil {
+		return Result{}, err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`
+		INSERT INTO rate_limit_buckets (key, tokens, updated_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (key) DO NOTHING
+	`, key, policy.Limit, now); err != nil {
+		return Result{}, fmt.Errorf("failed to create bucket: %w", err)
+	}
+
+	var tokens float64
+	var last time.Time
+	if err := tx.QueryRow(`
+		SELECT tokens, updated_at FROM rate_limit_buckets WHERE key = $1 FOR UPDATE
+	`, key).Scan(&tokens, &last); err != nil {
+		return Result{}, fmt.Errorf("failed to read bucket: %w", err)
+	}
+
+	tokens, result := refill(tokens, last, policy, now)
+
+	if _, err := tx.Exec(`
+		UPDATE rate_limit_buckets SET tokens = $2, updated_at = $3 WHERE key = $1
+	`, key, tokens, now); err != nil {
+		return Result{}, fmt.Errorf("failed to update bucket: %w", err)
+	}
+
+	return result, tx.Commit()
+}
+
+// Cleanup deletes buckets that have not been touched for longer than maxIdle.
+func (s *PostgresStore) Cleanup(maxIdle time.Duration) (int64, error) {
+	result, err := database.DB.Exec(`
+		DELETE FROM rate_limit_buckets WHERE updated_at < $1
+	`, time.Now().Add(-maxIdle))
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,143 @@
+package ratelimit
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Sea-Chels/go-practice-1/internal/auth"
+	"github.com/Sea-Chels/go-practice-1/internal/utils"
+)
+
+// Policy allows Limit requests per Period with a token bucket: the bucket
+// holds at most Limit tokens and refills continuously at Limit/Period.
+type Policy struct {
+	Name   string
+	Limit  int
+	Period time.Duration
+}
+
+// Result describes the state of a bucket after a request tried to take a token.
+type Result struct {
+	Allowed   bool
+	Remaining int
+	// RetryAfter is how long until the next token is available.
+	RetryAfter time.Duration
+	// ResetAfter is how long until the bucket is full again.
+	ResetAfter time.Duration
+}
+
+// Store keeps token buckets. Implementations must be safe for concurrent use.
+type Store interface {
+	Take(key string, policy Policy, now time.Time) (Result, error)
+}
+
+// refill computes the state of a bucket that held tokens at last and takes a
+// token from it if one is available. Both stores share this arithmetic.
+func refill(tokens float64, last time.Time, policy Policy, now time.Time) (float64, Result) {
+	capacity := float64(policy.Limit)
+	rate := capacity / policy.Period.Seconds()
+
+	elapsed := now.Sub(last).Seconds()
+	if elapsed < 0 {
+		elapsed = 0
+	}
+	tokens = math.Min(capacity, tokens+elapsed*rate)
+
+	result := Result{}
+	if tokens >= 1 {
+		tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = time.Duration((1 - tokens) / rate * float64(time.Second))
+	}
+	result.Remaining = int(math.Floor(tokens))
+	result.ResetAfter = time.Duration((capacity - tokens) / rate * float64(time.Second))
+	return tokens, result
+}
+
+// ParsePolicy reads a policy such as "100/1m" from the environment variable
+// key, falling back to the given default.
+func ParsePolicy(name, key string, fallback Policy) Policy {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+
+	parts := strings.SplitN(value, "/", 2)
+	limit, err := strconv.Atoi(parts[0])
+	if err != nil || limit <= 0 || len(parts) != 2 {
+		log.Printf("Invalid %s=%q, using %d/%s", key, value, fallback.Limit, fallback.Period)
+		return fallback
+	}
+	period, err := time.ParseDuration(parts[1])
+	if err != nil || period <= 0 {
+		log.Printf("Invalid %s=%q, using %d/%s", key, value, fallback.Limit, fallback.Period)
+		return fallback
+	}
+
+	return Policy{Name: name, Limit: limit, Period: period}
+}
+
+// NewStoreFromEnv returns the store selected by RATE_LIMIT_STORE ("memory",
+// the default, or "postgres").
+func NewStoreFromEnv() (Store, error) {
+	switch os.Getenv("RATE_LIMIT_STORE") {
+	case "", "memory":
+		return NewMemoryStore(), nil
+	case "postgres":
+		return NewPostgresStore(), nil
+	}
+	return nil, fmt.Errorf("unknown RATE_LIMIT_STORE %q", os.Getenv("RATE_LIMIT_STORE"))
+}
+
+// Limit rejects requests with 429 once the caller exhausts policy. Callers
+// are identified by their API key or user ID when the request is
+// authenticated, which requires Limit to be wrapped by auth.JWTMiddleware or
+// auth.Authenticate, and by IP otherwise. Wrapping the authentication
+// middleware instead limits by IP before credentials are checked.
+func Limit(store Store, policy Policy, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		key := policy.Name + ":ip:" + utils.ClientIP(r)
+		if claims, ok := auth.GetUserFromContext(r.Context()); ok {
+			if claims.APIKeyID != 0 {
+				key = policy.Name + ":api_key:" + strconv.Itoa(claims.APIKeyID)
+			} else {
+				key = policy.Name + ":user:" + strconv.Itoa(claims.UserID)
+			}
+		}
+
+		result, err := store.Take(key, policy, time.Now())
+		if err != nil {
+			// Fail open: an unavailable store should not take the API down.
+			log.Printf("Rate limiter error for %s: %v", key, err)
+			next(w, r)
+			return
+		}
+
+		w.Header().Set("RateLimit-Limit", strconv.Itoa(policy.Limit))
+		w.Header().Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		w.Header().Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(result.ResetAfter)))
+
+		if !result.Allowed {
+			w.Header().Set("Retry-After", strconv.Itoa(ceilSeconds(result.RetryAfter)))
+			utils.ErrorResponse(w, "Rate limit exceeded", http.StatusTooManyRequests)
+			return
+		}
+
+		next(w, r)
+	}
+}
+
+func ceilSeconds(d time.Duration) int {
+	seconds := int(math.Ceil(d.Seconds()))
+	if seconds < 0 {
+		return 0
+	}
+	return seconds
+}
@@ -0,0 +1,260 @@
+package students
+
+import (
+	"database/sql"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Sea-Chels/go-practice-1/internal/audit"
+	"github.com/Sea-Chels/go-practice-1/internal/models"
+	"github.com/Sea-Chels/go-practice-1/internal/utils"
+	"github.com/lib/pq"
+)
+
+// MaxImportRows caps the number of data rows accepted in one import.
+const MaxImportRows = 10000
+
+const maxExternalIDLength = 64
+
+// ErrInvalidFile wraps every error caused by the uploaded file rather than
+// the database.
+var ErrInvalidFile = errors.New("invalid CSV file")
+
+type ImportOptions struct {
+	// DryRun validates and applies every row inside a transaction that is
+	// rolled back, so the report reflects database constraints too.
+	DryRun bool
+	// ActorID is recorded in the audit log, nil for imports from the CLI.
+	ActorID   *int
+	IPAddress string
+}
+
+// importRow is a validated CSV row ready to be written.
+type importRow struct {
+	line       int
+	name       string
+	grade      int
+	externalID string
+	status     string
+}
+
+// Import reads a CSV roster with a header row containing name and grade and
+// optionally external_id and status, which defaults to active. Valid rows
+// are applied in a single transaction; rows with an external_id update the
+// student holding that ID, restoring it if it was deleted, and all other rows
+// create new students. Every row sets the student's status, so a graduated or
+// withdrawn student listed again comes back active unless the row says
+// otherwise. Invalid rows are skipped and listed in the report.
+//
+// An error is only returned when the file as a whole cannot be processed;
+// it wraps ErrInvalidFile when the file is at fault.
+func Import(db *sql.DB, r io.Reader, opts ImportOptions) (*models.StudentImportReport, error) {
+	report := &models.StudentImportReport{DryRun: opts.DryRun, Errors: []models.StudentImportRowError{}}
+
+	rows, err := parseImportCSV(r, report)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	for _, row := range rows {
+		created, err := applyImportRow(tx, row, now)
+		var rowErr *pq.Error
+		if errors.As(err, &rowErr) {
+			report.Errors = append(report.Errors, models.StudentImportRowError{
+				Row: row.line, ExternalID: row.externalID, Errors: []string{rowErr.Message},
+			})
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		if created {
+			report.Created++
+		} else {
+			report.Updated++
+		}
+	}
+	sort.SliceStable(report.Errors, func(i, j int) bool { return report.Errors[i].Row < report.Errors[j].Row })
+	report.Failed = len(report.Errors)
+
+	if opts.DryRun {
+		return report, nil
+	}
+
+	if err := audit.Record(tx, audit.Entry{
+		ActorID:    opts.ActorID,
+		Action:     audit.ActionStudentsImported,
+		EntityType: "student",
+		Details: map[string]interface{}{
+			"total_rows": report.TotalRows,
+			"created":    report.Created,
+			"updated":    report.Updated,
+			"failed":     report.Failed,
+		},
+		IPAddress: opts.IPAddress,
+	}); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// parseImportCSV validates every row, recording invalid ones in report.
+func parseImportCSV(r io.Reader, report *models.StudentImportReport) ([]importRow, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, fmt.Errorf("%w: file is empty", ErrInvalidFile)
+	} else if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidFile, err)
+	}
+
+	columns := map[string]int{}
+	for i, name := range header {
+		if i == 0 {
+			name = strings.TrimPrefix(name, "\ufeff")
+		}
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, required := range []string{"name", "grade"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("%w: header must contain a %q column", ErrInvalidFile, required)
+		}
+	}
+
+	field := func(record []string, name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	var rows []importRow
+	seen := map[string]int{}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+
+		report.TotalRows++
+		if report.TotalRows > MaxImportRows {
+			return nil, fmt.Errorf("%w: file must not contain more than %d rows", ErrInvalidFile, MaxImportRows)
+		}
+
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			report.Errors = append(report.Errors, models.StudentImportRowError{
+				Row: parseErr.Line, Errors: []string{parseErr.Err.Error()},
+			})
+			continue
+		} else if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidFile, err)
+		}
+
+		line, _ := reader.FieldPos(0)
+		row := importRow{line: line, name: field(record, "name"), externalID: field(record, "external_id"),
+			status: strings.ToLower(field(record, "status"))}
+		if row.status == "" {
+			row.status = models.StudentStatusActive
+		}
+
+		var problems []string
+		if err := utils.ValidateStudentName(row.name); err != nil {
+			problems = append(problems, err.Error())
+		}
+		if grade, err := strconv.Atoi(field(record, "grade")); err != nil {
+			problems = append(problems, "grade must be a whole number")
+		} else if err := utils.ValidateGrade(grade); err != nil {
+			problems = append(problems, err.Error())
+		} else {
+			row.grade = grade
+		}
+		if err := utils.ValidateStudentStatus(row.status); err != nil {
+			problems = append(problems, err.Error())
+		}
+		if len(row.externalID) > maxExternalIDLength {
+			problems = append(problems, fmt.Sprintf("external_id must not exceed %d characters", maxExternalIDLength))
+		} else if first, ok := seen[row.externalID]; ok && row.externalID != "" {
+			problems = append(problems, fmt.Sprintf("external_id is already used on row %d", first))
+		}
+
+		if len(problems) > 0 {
+			report.Errors = append(report.Errors, models.StudentImportRowError{
+				Row: line, ExternalID: row.externalID, Errors: problems,
+			})
+			continue
+		}
+
+		if row.externalID != "" {
+			seen[row.externalID] = line
+		}
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+// applyImportRow writes one row inside a savepoint, so a row rejected by the
+// database (returned as *pq.Error) does not abort the rest of the import. It
+// reports whether a student was created. graduated_at follows the status the
+// same way as when a student is edited.
+func applyImportRow(tx *sql.Tx, row importRow, now time.Time) (bool, error) {
+	if _, err := tx.Exec(`SAVEPOINT import_row`); err != nil {
+		return false, err
+	}
+
+	var created bool
+	var err error
+	firstName, lastName := utils.SplitStudentName(row.name)
+	if row.externalID == "" {
+		_, err = tx.Exec(`
+			INSERT INTO students (name, first_name, last_name, grade, status, graduated_at, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, CASE WHEN $5::varchar = 'graduated' THEN CURRENT_DATE END, $6, $6)
+		`, row.name, firstName, lastName, row.grade, row.status, now)
+		created = true
+	} else {
+		// xmax is zero only for rows inserted by this statement.
+		err = tx.QueryRow(`
+			INSERT INTO students (name, first_name, last_name, grade, external_id, status, graduated_at,
+			                      created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, CASE WHEN $6::varchar = 'graduated' THEN CURRENT_DATE END, $7, $7)
+			ON CONFLICT (external_id) WHERE external_id IS NOT NULL DO UPDATE
+			SET name = EXCLUDED.name, first_name = EXCLUDED.first_name, last_name = EXCLUDED.last_name,
+			    grade = EXCLUDED.grade, status = EXCLUDED.status,
+			    graduated_at = CASE WHEN EXCLUDED.status = 'graduated'
+			                        THEN COALESCE(students.graduated_at, CURRENT_DATE) END,
+			    updated_at = EXCLUDED.updated_at, deleted_at = NULL, version = students.version + 1
+			RETURNING xmax = 0
+		`, row.name, firstName, lastName, row.grade, row.externalID, row.status, now).Scan(&created)
+	}
+
+	if err != nil {
+		if _, rollbackErr := tx.Exec(`ROLLBACK TO SAVEPOINT import_row`); rollbackErr != nil {
+			return false, rollbackErr
+		}
+		return false, err
+	}
+
+	_, err = tx.Exec(`RELEASE SAVEPOINT import_row`)
+	return created, err
+}
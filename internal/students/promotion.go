@@ -0,0 +1,362 @@
+package students
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/Sea-Chels/go-practice-1/internal/audit"
+	"github.com/Sea-Chels/go-practice-1/internal/models"
+	"github.com/lib/pq"
+)
+
+// promotionCooldown guards against promoting everyone twice by accident.
+const promotionCooldown = 30 * 24 * time.Hour
+
+var (
+	// ErrInvalidPromotion wraps errors in the promotion request itself.
+	ErrInvalidPromotion = errors.New("invalid promotion request")
+	// ErrRecentPromotion is returned when a run happened within the cooldown
+	// and the request was not forced.
+	ErrRecentPromotion  = errors.New("a promotion was already run recently")
+	ErrRunNotFound      = errors.New("promotion run not found")
+	ErrRunNotRevertible = errors.New("promotion run cannot be reverted")
+)
+
+// activeStudents selects students that take part in a promotion.
+const activeStudents = `deleted_at IS NULL AND status = 'active'`
+
+// promotionPlan classifies every active student. $1 is the hold-back list.
+const promotionPlan = `
+	SELECT id, name, grade,
+	       CASE WHEN id = ANY($1::int[]) THEN 'held_back'
+	            WHEN grade = 12 THEN 'graduated'
+	            ELSE 'promoted' END AS action,
+	       CASE WHEN id = ANY($1::int[]) OR grade = 12 THEN grade ELSE grade + 1 END AS new_grade
+	FROM students
+	WHERE ` + activeStudents
+
+type PromotionOptions struct {
+	HoldBack       []int
+	GraduationDate time.Time
+	Force          bool
+	ActorID        *int
+	IPAddress      string
+}
+
+// ParsePromotionRequest converts an API or CLI request into options.
+func ParsePromotionRequest(req models.PromotionRequest) (PromotionOptions, error) {
+	opts := PromotionOptions{HoldBack: req.HoldBack, Force: req.Force}
+
+	date, err := time.Parse("2006-01-02", req.GraduationDate)
+	if req.GraduationDate == "" {
+		now := time.Now()
+		date, err = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC), nil
+	}
+	if err != nil {
+		return opts, fmt.Errorf("%w: graduation_date must be in YYYY-MM-DD format", ErrInvalidPromotion)
+	}
+	opts.GraduationDate = date
+
+	for _, id := range req.HoldBack {
+		if id <= 0 {
+			return opts, fmt.Errorf("%w: hold_back must contain student IDs", ErrInvalidPromotion)
+		}
+	}
+	return opts, nil
+}
+
+// dbQuerier is satisfied by both *sql.DB and *sql.Tx.
+type dbQuerier interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+// checkHoldBack makes sure every held back student takes part in the run.
+func checkHoldBack(db dbQuerier, holdBack []int) error {
+	if len(holdBack) == 0 {
+		return nil
+	}
+
+	rows, err := db.Query(`SELECT id FROM students WHERE id = ANY($1::int[]) AND `+activeStudents, pq.Array(holdBack))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	found := map[int]bool{}
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return err
+		}
+		found[id] = true
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, id := range holdBack {
+		if !found[id] {
+			return fmt.Errorf("%w: student %d in hold_back is not an active student", ErrInvalidPromotion, id)
+		}
+	}
+	return nil
+}
+
+// PreviewPromotion reports what ExecutePromotion would do without changing
+// anything.
+func PreviewPromotion(db *sql.DB, opts PromotionOptions) (*models.PromotionPreview, error) {
+	if err := checkHoldBack(db, opts.HoldBack); err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Query(promotionPlan+` ORDER BY grade, name, id`, pq.Array(opts.HoldBack))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	preview := &models.PromotionPreview{
+		GraduationDate: opts.GraduationDate.Format("2006-01-02"),
+		ByGrade:        []models.PromotionGradeCount{},
+		Graduating:     []models.PromotionStudent{},
+		HeldBackList:   []models.PromotionStudent{},
+	}
+	byGrade := map[int]int{}
+	for rows.Next() {
+		var student models.PromotionStudent
+		var action string
+		var newGrade int
+		if err := rows.Scan(&student.ID, &student.Name, &student.Grade, &action, &newGrade); err != nil {
+			return nil, err
+		}
+
+		switch action {
+		case "promoted":
+			preview.Promoted++
+			byGrade[student.Grade]++
+		case "graduated":
+			preview.Graduated++
+			preview.Graduating = append(preview.Graduating, student)
+		case "held_back":
+			preview.HeldBack++
+			preview.HeldBackList = append(preview.HeldBackList, student)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for grade, count := range byGrade {
+		preview.ByGrade = append(preview.ByGrade, models.PromotionGradeCount{Grade: grade, Students: count, NextGrade: grade + 1})
+	}
+	sort.Slice(preview.ByGrade, func(i, j int) bool { return preview.ByGrade[i].Grade < preview.ByGrade[j].Grade })
+
+	return preview, nil
+}
+
+// ExecutePromotion moves every active student up one grade, graduates grade
+// 12 students and leaves held back students unchanged, recording each change
+// so the run can be reverted.
+func ExecutePromotion(db *sql.DB, opts PromotionOptions) (*models.PromotionRun, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// Serializes concurrent runs: the second waits and then sees the first.
+	if _, err := tx.Exec(`LOCK TABLE promotion_runs IN EXCLUSIVE MODE`); err != nil {
+		return nil, err
+	}
+
+	if !opts.Force {
+		var last sql.NullTime
+		if err := tx.QueryRow(`
+			SELECT MAX(executed_at) FROM promotion_runs WHERE reverted_at IS NULL
+		`).Scan(&last); err != nil {
+			return nil, err
+		}
+		if last.Valid && time.Since(last.Time) < promotionCooldown {
+			return nil, fmt.Errorf("%w on %s; revert it or force the run", ErrRecentPromotion, last.Time.Format("2006-01-02"))
+		}
+	}
+
+	if _, err := tx.Exec(`SELECT id FROM students WHERE ` + activeStudents + ` FOR UPDATE`); err != nil {
+		return nil, err
+	}
+	if err := checkHoldBack(tx, opts.HoldBack); err != nil {
+		return nil, err
+	}
+
+	var runID int
+	if err := tx.QueryRow(`
+		INSERT INTO promotion_runs (graduation_date, executed_by) VALUES ($1, $2) RETURNING id
+	`, opts.GraduationDate, opts.ActorID).Scan(&runID); err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.Exec(`
+		INSERT INTO promotion_run_students (run_id, student_id, action, previous_grade, new_grade)
+		SELECT $2, id, action, grade, new_grade FROM (`+promotionPlan+`) plan
+	`, pq.Array(opts.HoldBack), runID); err != nil {
+		return nil, fmt.Errorf("failed to record promotion: %w", err)
+	}
+
+	if _, err := tx.Exec(`
+		UPDATE students s
+		SET grade = p.new_grade,
+		    graduated_at = CASE WHEN p.action = 'graduated' THEN $2::date END,
+		    status = CASE WHEN p.action = 'graduated' THEN 'graduated' ELSE 'active' END,
+		    updated_at = $3, version = s.version + 1
+		FROM promotion_run_students p
+		WHERE p.run_id = $1 AND p.student_id = s.id AND p.action <> 'held_back'
+	`, runID, opts.GraduationDate, time.Now()); err != nil {
+		return nil, fmt.Errorf("failed to promote students: %w", err)
+	}
+
+	run, err := scanPromotionRun(tx.QueryRow(`
+		UPDATE promotion_runs r
+		SET promoted_count = c.promoted, graduated_count = c.graduated, held_back_count = c.held_back
+		FROM (
+			SELECT COUNT(*) FILTER (WHERE action = 'promoted') AS promoted,
+			       COUNT(*) FILTER (WHERE action = 'graduated') AS graduated,
+			       COUNT(*) FILTER (WHERE action = 'held_back') AS held_back
+			FROM promotion_run_students WHERE run_id = $1
+		) c
+		WHERE r.id = $1
+		RETURNING `+promotionRunColumns("r"), runID))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := audit.Record(tx, audit.Entry{
+		ActorID:    opts.ActorID,
+		Action:     audit.ActionPromotionExecuted,
+		EntityType: "promotion_run",
+		EntityID:   fmt.Sprint(run.ID),
+		Details: map[string]interface{}{
+			"promoted":        run.Promoted,
+			"graduated":       run.Graduated,
+			"held_back":       run.HeldBack,
+			"graduation_date": opts.GraduationDate.Format("2006-01-02"),
+		},
+		IPAddress: opts.IPAddress,
+	}); err != nil {
+		return nil, err
+	}
+
+	return run, tx.Commit()
+}
+
+// RevertPromotion undoes the most recent run. Students edited since the run
+// (a different grade or status than the run left them in) are
+// skipped and counted in the result.
+func RevertPromotion(db *sql.DB, runID int, actorID *int, ipAddress string) (*models.PromotionRun, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`LOCK TABLE promotion_runs IN EXCLUSIVE MODE`); err != nil {
+		return nil, err
+	}
+
+	run, err := scanPromotionRun(tx.QueryRow(`
+		SELECT `+promotionRunColumns("promotion_runs")+` FROM promotion_runs WHERE id = $1
+	`, runID))
+	if err == sql.ErrNoRows {
+		return nil, ErrRunNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	if run.RevertedAt != nil {
+		return nil, fmt.Errorf("%w: it was already reverted", ErrRunNotRevertible)
+	}
+
+	var newer bool
+	if err := tx.QueryRow(`
+		SELECT EXISTS(SELECT 1 FROM promotion_runs WHERE id > $1 AND reverted_at IS NULL)
+	`, runID).Scan(&newer); err != nil {
+		return nil, err
+	}
+	if newer {
+		return nil, fmt.Errorf("%w: revert the later runs first", ErrRunNotRevertible)
+	}
+
+	result, err := tx.Exec(`
+		UPDATE students s
+		SET grade = p.previous_grade, graduated_at = NULL, status = 'active',
+		    updated_at = $2, version = s.version + 1
+		FROM promotion_run_students p
+		WHERE p.run_id = $1 AND p.student_id = s.id AND p.action <> 'held_back'
+		  AND s.grade = p.new_grade
+		  AND s.status = CASE WHEN p.action = 'graduated' THEN 'graduated' ELSE 'active' END
+	`, runID, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to revert students: %w", err)
+	}
+	reverted, _ := result.RowsAffected()
+
+	now := time.Now()
+	if _, err := tx.Exec(`
+		UPDATE promotion_runs SET reverted_at = $2, reverted_by = $3 WHERE id = $1
+	`, runID, now, actorID); err != nil {
+		return nil, err
+	}
+	run.RevertedAt = &now
+	run.RevertedBy = actorID
+	run.Skipped = run.Promoted + run.Graduated - int(reverted)
+
+	if err := audit.Record(tx, audit.Entry{
+		ActorID:    actorID,
+		Action:     audit.ActionPromotionReverted,
+		EntityType: "promotion_run",
+		EntityID:   fmt.Sprint(run.ID),
+		Details:    map[string]interface{}{"reverted": reverted, "skipped": run.Skipped},
+		IPAddress:  ipAddress,
+	}); err != nil {
+		return nil, err
+	}
+
+	return run, tx.Commit()
+}
+
+// ListPromotionRuns returns every run, newest first.
+func ListPromotionRuns(db *sql.DB) ([]models.PromotionRun, error) {
+	rows, err := db.Query(`SELECT ` + promotionRunColumns("promotion_runs") + ` FROM promotion_runs ORDER BY id DESC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	runs := []models.PromotionRun{}
+	for rows.Next() {
+		run, err := scanPromotionRun(rows)
+		if err != nil {
+			return nil, err
+		}
+		runs = append(runs, *run)
+	}
+	return runs, rows.Err()
+}
+
+func promotionRunColumns(table string) string {
+	return fmt.Sprintf(`%[1]s.id, %[1]s.graduation_date, %[1]s.promoted_count, %[1]s.graduated_count,
+		%[1]s.held_back_count, %[1]s.executed_by, %[1]s.executed_at, %[1]s.reverted_by, %[1]s.reverted_at`, table)
+}
+
+func scanPromotionRun(row interface{ Scan(...interface{}) error }) (*models.PromotionRun, error) {
+	var run models.PromotionRun
+	err := row.Scan(&run.ID, &run.GraduationDate, &run.Promoted, &run.Graduated, &run.HeldBack,
+		&run.ExecutedBy, &run.ExecutedAt, &run.RevertedBy, &run.RevertedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &run, nil
+}
@@ -0,0 +1,44 @@
+// Package students holds student operations shared by the API and the
+// command line tools.
+package students
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/Sea-Chels/go-practice-1/internal/audit"
+)
+
+// PurgeDeleted permanently removes students that were soft-deleted before
+// cutoff and returns how many were removed.
+func PurgeDeleted(db *sql.DB, cutoff time.Time) (int64, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`DELETE FROM students WHERE deleted_at IS NOT NULL AND deleted_at < $1`, cutoff)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge students: %w", err)
+	}
+
+	purged, _ := result.RowsAffected()
+	if purged == 0 {
+		return 0, nil
+	}
+
+	if err := audit.Record(tx, audit.Entry{
+		Action:     audit.ActionStudentPurged,
+		EntityType: "student",
+		Details: map[string]interface{}{
+			"count":          purged,
+			"deleted_before": cutoff,
+		},
+	}); err != nil {
+		return 0, err
+	}
+
+	return purged, tx.Commit()
+}
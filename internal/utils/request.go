@@ -0,0 +1,27 @@
+package utils
+
+import (
+	"net"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// ClientIP returns the address of the client. Forwarding headers are only
+// honored when TRUST_PROXY_HEADERS=true, since clients can set them freely.
+func ClientIP(r *http.Request) string {
+	if os.Getenv("TRUST_PROXY_HEADERS") == "true" {
+		if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+			return strings.TrimSpace(strings.Split(forwarded, ",")[0])
+		}
+		if realIP := r.Header.Get("X-Real-IP"); realIP != "" {
+			return strings.TrimSpace(realIP)
+		}
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
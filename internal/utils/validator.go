@@ -4,10 +4,17 @@ import (
 	"fmt"
 	"regexp"
 	"strings"
+	"time"
 )
 
 var emailRegex = regexp.MustCompile(`^[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}$`)
 
+var studentNumberRegex = regexp.MustCompile(`^[A-Za-z0-9-]{1,32}$`)
+
+var phoneRegex = regexp.MustCompile(`^\+?[0-9 ().-]{7,32}$`)
+
+var courseCodeRegex = regexp.MustCompile(`^[A-Z0-9][A-Z0-9-]{1,19}$`)
+
 func ValidateEmail(email string) error {
 	if email == "" {
 		return fmt.Errorf("email is required")
@@ -44,6 +51,15 @@ func ValidatePassword(password string) error {
 }
 
 func ValidateStudentName(name string) error {
+	return validatePersonName(name)
+}
+
+func ValidateTeacherName(name string) error {
+	return validatePersonName(name)
+}
+
+// validatePersonName checks the full name of a student or teacher.
+func validatePersonName(name string) error {
 	name = strings.TrimSpace(name)
 	if name == "" {
 		return fmt.Errorf("name is required")
@@ -62,4 +78,168 @@ func ValidateGrade(grade int) error {
 		return fmt.Errorf("grade must be between 1 and 12")
 	}
 	return nil
-}
\ No newline at end of file
+}
+
+// SplitStudentName splits a full name at the first space into first and last
+// name, the same way migration 017 split existing names.
+func SplitStudentName(name string) (string, string) {
+	first, last, _ := strings.Cut(strings.TrimSpace(name), " ")
+	return first, strings.TrimSpace(last)
+}
+
+func ValidateFirstName(name string) error {
+	if strings.TrimSpace(name) == "" {
+		return fmt.Errorf("first_name is required")
+	}
+	return ValidateMaxLength("first_name", name, 255)
+}
+
+func ValidateLastName(name string) error {
+	if strings.TrimSpace(name) == "" {
+		return fmt.Errorf("last_name is required")
+	}
+	return ValidateMaxLength("last_name", name, 255)
+}
+
+// ValidatePhone accepts digits with an optional leading + and the usual
+// separators, and at least 7 digits.
+func ValidatePhone(field, phone string) error {
+	digits := 0
+	for _, char := range phone {
+		if char >= '0' && char <= '9' {
+			digits++
+		}
+	}
+	if !phoneRegex.MatchString(phone) || digits < 7 {
+		return fmt.Errorf("%s must be a phone number with at least 7 digits", field)
+	}
+	return nil
+}
+
+func ValidateGuardianRelationship(relationship string) error {
+	switch relationship {
+	case "mother", "father", "parent", "stepparent", "grandparent", "foster_parent",
+		"legal_guardian", "sibling", "relative", "other":
+		return nil
+	}
+	return fmt.Errorf("relationship must be one of mother, father, parent, stepparent, grandparent, " +
+		"foster_parent, legal_guardian, sibling, relative, other")
+}
+
+// ValidateCourseCode expects an upper-case code such as MATH-101.
+func ValidateCourseCode(code string) error {
+	if !courseCodeRegex.MatchString(code) {
+		return fmt.Errorf("code must be 2 to 20 upper-case letters, digits or dashes")
+	}
+	return nil
+}
+
+// ValidateMeetingDays accepts each of M T W R F S U (Monday to Sunday) at
+// most once, for example MWF or TR.
+func ValidateMeetingDays(days string) error {
+	seen := map[rune]bool{}
+	for _, day := range days {
+		if !strings.ContainsRune("MTWRFSU", day) || seen[day] {
+			return fmt.Errorf("meeting_days must use each of M, T, W, R, F, S, U at most once")
+		}
+		seen[day] = true
+	}
+	return nil
+}
+
+// ParseTimeOfDay parses an optional HH:MM time. An empty string yields nil.
+func ParseTimeOfDay(field, value string) (*time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, nil
+	}
+	parsed, err := time.Parse("15:04", value)
+	if err != nil {
+		return nil, fmt.Errorf("%s must be in HH:MM format", field)
+	}
+	return &parsed, nil
+}
+
+func ValidateTermType(termType string) error {
+	switch termType {
+	case "semester", "trimester", "quarter", "other":
+		return nil
+	}
+	return fmt.Errorf("type must be one of semester, trimester, quarter, other")
+}
+
+func ValidateCalendarEventType(eventType string) error {
+	switch eventType {
+	case "holiday", "non_instructional":
+		return nil
+	}
+	return fmt.Errorf("type must be one of holiday, non_instructional")
+}
+
+func ValidateAttendanceStatus(status string) error {
+	switch status {
+	case "present", "absent", "tardy", "excused":
+		return nil
+	}
+	return fmt.Errorf("status must be one of present, absent, tardy, excused")
+}
+
+// ValidateMaxLength checks optional free-text fields.
+func ValidateMaxLength(field, value string, max int) error {
+	if len(strings.TrimSpace(value)) > max {
+		return fmt.Errorf("%s must not exceed %d characters", field, max)
+	}
+	return nil
+}
+
+func ValidateStudentNumber(number string) error {
+	if number != "" && !studentNumberRegex.MatchString(number) {
+		return fmt.Errorf("student_number must be up to 32 letters, digits or dashes")
+	}
+	return nil
+}
+
+func ValidateDateOfBirth(dateOfBirth time.Time) error {
+	if dateOfBirth.After(time.Now()) {
+		return fmt.Errorf("date_of_birth must not be in the future")
+	}
+	if dateOfBirth.Year() < 1900 {
+		return fmt.Errorf("date_of_birth must not be before 1900")
+	}
+	return nil
+}
+
+func ValidateStudentStatus(status string) error {
+	switch status {
+	case "active", "withdrawn", "graduated":
+		return nil
+	}
+	return fmt.Errorf("status must be one of active, withdrawn, graduated")
+}
+
+func ValidateSubject(subject string) error {
+	if len(strings.TrimSpace(subject)) > 100 {
+		return fmt.Errorf("subject must not exceed 100 characters")
+	}
+	return nil
+}
+
+// ParseDate parses an optional YYYY-MM-DD date. An empty string yields nil.
+func ParseDate(field, value string) (*time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, nil
+	}
+	date, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return nil, fmt.Errorf("%s must be in YYYY-MM-DD format", field)
+	}
+	return &date, nil
+}
+
+func ValidateFullName(name string) error {
+	if len(strings.TrimSpace(name)) > 255 {
+		return fmt.Errorf("full_name must not exceed 255 characters")
+	}
+	return nil
+}
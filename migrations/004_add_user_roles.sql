@@ -0,0 +1,9 @@
+-- Migration: add_user_roles
+-- Users that existed before roles were introduced keep full access; new
+-- users default to read-only.
+
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS role VARCHAR(20) NOT NULL DEFAULT 'admin'
+    CHECK (role IN ('admin', 'teacher', 'staff', 'read_only'));
+
+ALTER TABLE users ALTER COLUMN role SET DEFAULT 'read_only';
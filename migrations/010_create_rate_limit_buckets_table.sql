@@ -0,0 +1,10 @@
+-- Migration: create_rate_limit_buckets_table
+-- Token buckets for the Postgres-backed rate limiter (RATE_LIMIT_STORE=postgres).
+
+CREATE TABLE IF NOT EXISTS rate_limit_buckets (
+    key VARCHAR(255) PRIMARY KEY,
+    tokens DOUBLE PRECISION NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_rate_limit_buckets_updated_at ON rate_limit_buckets(updated_at);
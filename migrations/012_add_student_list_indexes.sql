@@ -0,0 +1,9 @@
+-- Migration: add_student_list_indexes
+-- Keyset pagination orders by the sort column with id as the tie breaker.
+-- The lower(name) index serves case-insensitive name prefix filters.
+
+CREATE INDEX IF NOT EXISTS idx_students_name_id ON students(name, id);
+CREATE INDEX IF NOT EXISTS idx_students_grade_id ON students(grade, id);
+CREATE INDEX IF NOT EXISTS idx_students_created_at_id ON students(created_at, id);
+CREATE INDEX IF NOT EXISTS idx_students_updated_at_id ON students(updated_at, id);
+CREATE INDEX IF NOT EXISTS idx_students_lower_name ON students(lower(name) text_pattern_ops);
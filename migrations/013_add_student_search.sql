@@ -0,0 +1,8 @@
+-- Migration: add_student_search
+-- Name search combines full-text matching (exact and prefix words) with
+-- trigram similarity so misspelled names are still found.
+
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX IF NOT EXISTS idx_students_name_trgm ON students USING GIN (name gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_students_name_fts ON students USING GIN (to_tsvector('simple', name));
@@ -0,0 +1,5 @@
+-- Migration: add_student_version
+-- Incremented on every write and exposed as the ETag of a student, so
+-- concurrent edits are detected instead of overwriting each other.
+
+ALTER TABLE students ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
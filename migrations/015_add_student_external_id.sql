@@ -0,0 +1,6 @@
+-- Migration: add_student_external_id
+-- Identifier from the district roster, used to match rows on re-import.
+
+ALTER TABLE students ADD COLUMN IF NOT EXISTS external_id VARCHAR(64);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_students_external_id ON students(external_id) WHERE external_id IS NOT NULL;
@@ -0,0 +1,28 @@
+-- Migration: add_student_promotion
+-- Graduated students keep grade 12 and get a graduation date. Each
+-- promotion run records every student it touched so it can be reverted.
+
+ALTER TABLE students ADD COLUMN IF NOT EXISTS graduated_at DATE;
+
+CREATE INDEX IF NOT EXISTS idx_students_graduated_at ON students(graduated_at);
+
+CREATE TABLE IF NOT EXISTS promotion_runs (
+    id SERIAL PRIMARY KEY,
+    graduation_date DATE NOT NULL,
+    promoted_count INTEGER NOT NULL DEFAULT 0,
+    graduated_count INTEGER NOT NULL DEFAULT 0,
+    held_back_count INTEGER NOT NULL DEFAULT 0,
+    executed_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    executed_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    reverted_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    reverted_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE TABLE IF NOT EXISTS promotion_run_students (
+    run_id INTEGER NOT NULL REFERENCES promotion_runs(id) ON DELETE CASCADE,
+    student_id INTEGER NOT NULL REFERENCES students(id) ON DELETE CASCADE,
+    action VARCHAR(20) NOT NULL CHECK (action IN ('promoted', 'graduated', 'held_back')),
+    previous_grade INTEGER NOT NULL,
+    new_grade INTEGER NOT NULL,
+    PRIMARY KEY (run_id, student_id)
+);
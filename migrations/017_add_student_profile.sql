@@ -0,0 +1,36 @@
+-- Migration: add_student_profile
+-- Profile fields for students. Existing rows get first_name and last_name by
+-- splitting name at the first space, their creation date as enrollment date
+-- and a status matching their graduation state.
+
+ALTER TABLE students ADD COLUMN IF NOT EXISTS first_name VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE students ADD COLUMN IF NOT EXISTS last_name VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE students ADD COLUMN IF NOT EXISTS preferred_name VARCHAR(100);
+ALTER TABLE students ADD COLUMN IF NOT EXISTS student_number VARCHAR(32);
+ALTER TABLE students ADD COLUMN IF NOT EXISTS date_of_birth DATE;
+ALTER TABLE students ADD COLUMN IF NOT EXISTS gender VARCHAR(50);
+ALTER TABLE students ADD COLUMN IF NOT EXISTS address_line1 VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE students ADD COLUMN IF NOT EXISTS address_line2 VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE students ADD COLUMN IF NOT EXISTS city VARCHAR(100) NOT NULL DEFAULT '';
+ALTER TABLE students ADD COLUMN IF NOT EXISTS state VARCHAR(100) NOT NULL DEFAULT '';
+ALTER TABLE students ADD COLUMN IF NOT EXISTS postal_code VARCHAR(20) NOT NULL DEFAULT '';
+ALTER TABLE students ADD COLUMN IF NOT EXISTS country VARCHAR(100) NOT NULL DEFAULT '';
+ALTER TABLE students ADD COLUMN IF NOT EXISTS enrollment_date DATE;
+ALTER TABLE students
+    ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'active'
+    CHECK (status IN ('active', 'withdrawn', 'graduated'));
+
+UPDATE students
+SET first_name = split_part(btrim(name), ' ', 1),
+    last_name = btrim(substr(btrim(name), length(split_part(btrim(name), ' ', 1)) + 1))
+WHERE first_name = '';
+
+UPDATE students SET enrollment_date = created_at::date WHERE enrollment_date IS NULL;
+ALTER TABLE students ALTER COLUMN enrollment_date SET DEFAULT CURRENT_DATE;
+ALTER TABLE students ALTER COLUMN enrollment_date SET NOT NULL;
+
+UPDATE students SET status = 'graduated' WHERE graduated_at IS NOT NULL AND status <> 'graduated';
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_students_student_number ON students(student_number) WHERE student_number IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_students_status ON students(status);
+CREATE INDEX IF NOT EXISTS idx_students_last_name_id ON students(last_name, id);
@@ -0,0 +1,37 @@
+-- Migration: create_guardians
+-- Guardians and emergency contacts. A student can have several guardians and
+-- a guardian several students; the link records how they are related and
+-- what the guardian is allowed to do. Priority 1 is called first.
+
+CREATE TABLE IF NOT EXISTS guardians (
+    id SERIAL PRIMARY KEY,
+    first_name VARCHAR(255) NOT NULL,
+    last_name VARCHAR(255) NOT NULL,
+    email VARCHAR(255),
+    phone VARCHAR(32) NOT NULL,
+    alternate_phone VARCHAR(32),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_guardians_last_name ON guardians(last_name, first_name);
+CREATE INDEX IF NOT EXISTS idx_guardians_deleted_at ON guardians(deleted_at);
+
+CREATE TABLE IF NOT EXISTS student_guardians (
+    student_id INTEGER NOT NULL REFERENCES students(id) ON DELETE CASCADE,
+    guardian_id INTEGER NOT NULL REFERENCES guardians(id) ON DELETE CASCADE,
+    relationship VARCHAR(20) NOT NULL CHECK (relationship IN (
+        'mother', 'father', 'parent', 'stepparent', 'grandparent', 'foster_parent',
+        'legal_guardian', 'sibling', 'relative', 'other'
+    )),
+    has_custody BOOLEAN NOT NULL DEFAULT FALSE,
+    can_pickup BOOLEAN NOT NULL DEFAULT FALSE,
+    emergency_contact BOOLEAN NOT NULL DEFAULT TRUE,
+    priority INTEGER NOT NULL DEFAULT 1 CHECK (priority >= 1),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (student_id, guardian_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_student_guardians_guardian_id ON student_guardians(guardian_id);
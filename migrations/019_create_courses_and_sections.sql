@@ -0,0 +1,50 @@
+-- Migration: create_courses_and_sections
+-- A course is taught in sections, each with a teacher, term, room and
+-- capacity. Students are enrolled into sections; dropping keeps the row with
+-- status 'dropped' so the history is preserved and re-enrolling reuses it.
+
+CREATE TABLE IF NOT EXISTS courses (
+    id SERIAL PRIMARY KEY,
+    code VARCHAR(20) UNIQUE NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    description TEXT,
+    credits NUMERIC(4, 2) NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_courses_deleted_at ON courses(deleted_at);
+
+CREATE TABLE IF NOT EXISTS sections (
+    id SERIAL PRIMARY KEY,
+    course_id INTEGER NOT NULL REFERENCES courses(id) ON DELETE CASCADE,
+    teacher_id INTEGER REFERENCES teachers(id) ON DELETE SET NULL,
+    section_number VARCHAR(10) NOT NULL,
+    term VARCHAR(50) NOT NULL,
+    room VARCHAR(50),
+    capacity INTEGER NOT NULL CHECK (capacity > 0),
+    meeting_days VARCHAR(7),
+    start_time TIME,
+    end_time TIME,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_sections_course_term_number
+    ON sections(course_id, term, section_number) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_sections_teacher_id ON sections(teacher_id);
+CREATE INDEX IF NOT EXISTS idx_sections_term ON sections(term);
+
+CREATE TABLE IF NOT EXISTS enrollments (
+    id SERIAL PRIMARY KEY,
+    section_id INTEGER NOT NULL REFERENCES sections(id) ON DELETE CASCADE,
+    student_id INTEGER NOT NULL REFERENCES students(id) ON DELETE CASCADE,
+    status VARCHAR(20) NOT NULL DEFAULT 'enrolled' CHECK (status IN ('enrolled', 'dropped')),
+    enrolled_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    dropped_at TIMESTAMP WITH TIME ZONE,
+    UNIQUE (section_id, student_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_enrollments_student_id ON enrollments(student_id);
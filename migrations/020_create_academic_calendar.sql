@@ -0,0 +1,47 @@
+-- Migration: create_academic_calendar
+-- School years are split into terms (semesters, quarters, ...). Terms of the
+-- same type and school years must not overlap; the API checks this. Calendar
+-- events mark holidays and other days without instruction.
+
+CREATE TABLE IF NOT EXISTS academic_years (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(50) UNIQUE NOT NULL,
+    start_date DATE NOT NULL,
+    end_date DATE NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CHECK (end_date > start_date)
+);
+
+CREATE TABLE IF NOT EXISTS terms (
+    id SERIAL PRIMARY KEY,
+    academic_year_id INTEGER NOT NULL REFERENCES academic_years(id) ON DELETE CASCADE,
+    name VARCHAR(50) NOT NULL,
+    type VARCHAR(20) NOT NULL CHECK (type IN ('semester', 'trimester', 'quarter', 'other')),
+    start_date DATE NOT NULL,
+    end_date DATE NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (academic_year_id, name),
+    CHECK (end_date > start_date)
+);
+
+CREATE INDEX IF NOT EXISTS idx_terms_dates ON terms(start_date, end_date);
+
+CREATE TABLE IF NOT EXISTS calendar_events (
+    id SERIAL PRIMARY KEY,
+    academic_year_id INTEGER NOT NULL REFERENCES academic_years(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    type VARCHAR(20) NOT NULL CHECK (type IN ('holiday', 'non_instructional')),
+    start_date DATE NOT NULL,
+    end_date DATE NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CHECK (end_date >= start_date)
+);
+
+CREATE INDEX IF NOT EXISTS idx_calendar_events_dates ON calendar_events(start_date, end_date);
+
+ALTER TABLE sections ADD COLUMN IF NOT EXISTS term_id INTEGER REFERENCES terms(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_sections_term_id ON sections(term_id);
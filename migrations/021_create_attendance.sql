@@ -0,0 +1,37 @@
+-- Migration: create_attendance
+-- Attendance is taken once per student and day, and optionally per section
+-- for schools that take it every period. Changing a recorded status is a
+-- correction, kept with its reason in attendance_corrections.
+
+CREATE TABLE IF NOT EXISTS attendance_records (
+    id SERIAL PRIMARY KEY,
+    student_id INTEGER NOT NULL REFERENCES students(id) ON DELETE CASCADE,
+    section_id INTEGER REFERENCES sections(id) ON DELETE CASCADE,
+    date DATE NOT NULL,
+    status VARCHAR(20) NOT NULL CHECK (status IN ('present', 'absent', 'tardy', 'excused')),
+    notes TEXT,
+    recorded_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- NULL section_ids are distinct in a plain unique index, so daily and
+-- per-section records each get their own.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_attendance_records_daily
+    ON attendance_records(student_id, date) WHERE section_id IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_attendance_records_section
+    ON attendance_records(student_id, section_id, date) WHERE section_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_attendance_records_section_date ON attendance_records(section_id, date);
+CREATE INDEX IF NOT EXISTS idx_attendance_records_date ON attendance_records(date);
+
+CREATE TABLE IF NOT EXISTS attendance_corrections (
+    id SERIAL PRIMARY KEY,
+    attendance_id INTEGER NOT NULL REFERENCES attendance_records(id) ON DELETE CASCADE,
+    previous_status VARCHAR(20) NOT NULL,
+    new_status VARCHAR(20) NOT NULL,
+    reason TEXT NOT NULL,
+    corrected_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    corrected_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_attendance_corrections_attendance_id ON attendance_corrections(attendance_id);
@@ -0,0 +1,71 @@
+-- Migration: create_gradebook
+-- Each section weighs its assignments by category. Scores carry late,
+-- missing and exempt flags, and averages are turned into letter grades with
+-- the section's grading scale or the default one.
+
+CREATE TABLE IF NOT EXISTS grading_scales (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(50) UNIQUE NOT NULL,
+    is_default BOOLEAN NOT NULL DEFAULT FALSE,
+    -- [{"letter": "A", "min_percent": 90}, ...], highest band first
+    bands JSONB NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_grading_scales_default ON grading_scales(is_default) WHERE is_default;
+
+INSERT INTO grading_scales (name, is_default, bands)
+SELECT 'Standard', TRUE, '[
+    {"letter": "A", "min_percent": 90},
+    {"letter": "B", "min_percent": 80},
+    {"letter": "C", "min_percent": 70},
+    {"letter": "D", "min_percent": 60},
+    {"letter": "F", "min_percent": 0}
+]'::jsonb
+WHERE NOT EXISTS (SELECT 1 FROM grading_scales);
+
+ALTER TABLE sections ADD COLUMN IF NOT EXISTS grading_scale_id INTEGER REFERENCES grading_scales(id) ON DELETE SET NULL;
+
+CREATE TABLE IF NOT EXISTS grade_categories (
+    id SERIAL PRIMARY KEY,
+    section_id INTEGER NOT NULL REFERENCES sections(id) ON DELETE CASCADE,
+    name VARCHAR(50) NOT NULL,
+    weight NUMERIC(5, 2) NOT NULL CHECK (weight > 0),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (section_id, name)
+);
+
+CREATE TABLE IF NOT EXISTS assignments (
+    id SERIAL PRIMARY KEY,
+    section_id INTEGER NOT NULL REFERENCES sections(id) ON DELETE CASCADE,
+    category_id INTEGER NOT NULL REFERENCES grade_categories(id) ON DELETE RESTRICT,
+    title VARCHAR(255) NOT NULL,
+    description TEXT,
+    max_points NUMERIC(7, 2) NOT NULL CHECK (max_points > 0),
+    due_date DATE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_assignments_section_id ON assignments(section_id);
+CREATE INDEX IF NOT EXISTS idx_assignments_category_id ON assignments(category_id);
+
+CREATE TABLE IF NOT EXISTS scores (
+    id SERIAL PRIMARY KEY,
+    assignment_id INTEGER NOT NULL REFERENCES assignments(id) ON DELETE CASCADE,
+    student_id INTEGER NOT NULL REFERENCES students(id) ON DELETE CASCADE,
+    points NUMERIC(7, 2) CHECK (points >= 0),
+    late BOOLEAN NOT NULL DEFAULT FALSE,
+    missing BOOLEAN NOT NULL DEFAULT FALSE,
+    exempt BOOLEAN NOT NULL DEFAULT FALSE,
+    comment TEXT,
+    graded_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (assignment_id, student_id),
+    CHECK (NOT (missing AND exempt))
+);
+
+CREATE INDEX IF NOT EXISTS idx_scores_student_id ON scores(student_id);
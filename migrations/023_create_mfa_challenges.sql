@@ -0,0 +1,13 @@
+-- Migration: create_mfa_challenges
+-- Tracks each MFA challenge token at /auth/mfa/verify so a token can only
+-- be used once and stops working after too many wrong codes.
+
+CREATE TABLE IF NOT EXISTS mfa_challenges (
+    token_hash VARCHAR(64) PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    failed_attempts INTEGER NOT NULL DEFAULT 0,
+    used_at TIMESTAMP WITH TIME ZONE,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_mfa_challenges_user_id ON mfa_challenges(user_id);
@@ -0,0 +1,18 @@
+-- Migration: add_student_guardian_priority_index
+-- Each guardian of a student has its own contact priority. Existing ties are
+-- broken in the order the contacts were listed before, by name.
+
+UPDATE student_guardians sg
+SET priority = ranked.position
+FROM (
+    SELECT l.student_id, l.guardian_id,
+           ROW_NUMBER() OVER (PARTITION BY l.student_id ORDER BY l.priority, g.last_name, g.first_name, g.id) AS position
+    FROM student_guardians l
+    JOIN guardians g ON g.id = l.guardian_id
+) ranked
+WHERE ranked.student_id = sg.student_id AND ranked.guardian_id = sg.guardian_id
+  AND sg.student_id IN (
+      SELECT student_id FROM student_guardians GROUP BY student_id, priority HAVING COUNT(*) > 1
+  );
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_student_guardians_priority ON student_guardians(student_id, priority);